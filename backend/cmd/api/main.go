@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
+	_ "time/tzdata" // user time zones must resolve without system zone data
 
 	"github.com/gerenciador-ferias/backend/internal/config"
 	"github.com/gerenciador-ferias/backend/internal/database"
 	"github.com/gerenciador-ferias/backend/internal/handlers"
 	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,6 +31,91 @@ func main() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	services.ConfigureActionLinks(services.ActionLinkConfig{
+		Secret:  cfg.JWTSecret,
+		BaseURL: cfg.ActionLinkBaseURL,
+		TTL:     time.Duration(cfg.ActionLinkTTLHours) * time.Hour,
+	})
+
+	services.ConfigureEmail(cfg.AppBaseURL)
+
+	services.ConfigureSlack(services.SlackConfig{
+		SigningSecret: cfg.SlackSigningSecret,
+		BotToken:      cfg.SlackBotToken,
+		APIURL:        cfg.SlackAPIURL,
+	})
+
+	// Deliver notifications outside the app on the configured channels
+	channels := []services.Channel{services.InAppChannel{}}
+	if cfg.SMTPHost != "" {
+		channels = append(channels, services.NewEmailChannel(db, services.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}))
+	}
+	if cfg.NotificationWebhookURL != "" {
+		channels = append(channels, services.NewWebhookChannel(cfg.NotificationWebhookURL))
+	}
+	eventChannels := map[models.NotificationType][]string{}
+	for notificationType, names := range cfg.NotificationEventChannels {
+		eventChannels[models.NotificationType(notificationType)] = names
+	}
+	dispatcher := services.NewDispatcher(db, services.DispatcherConfig{
+		DefaultChannels: cfg.NotificationChannels,
+		EventChannels:   eventChannels,
+		MaxAttempts:     cfg.NotificationMaxAttempts,
+		RetryDelay:      time.Duration(cfg.NotificationRetrySeconds) * time.Second,
+		SendTimeout:     30 * time.Second,
+		BatchSize:       50,
+	}, time.Minute, channels...)
+	services.SetDispatcher(dispatcher)
+
+	// Push notifications and status changes to connected clients of every instance
+	streamHub := services.NewStreamHub(db, cfg.DatabaseURL, time.Duration(cfg.StreamRetentionHours)*time.Hour)
+	services.SetStreamHub(streamHub)
+
+	// Post webhook events to external subscribers
+	webhookSender := services.NewWebhookSender(db, services.WebhookSenderConfig{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		RetryDelay:  time.Duration(cfg.WebhookRetrySeconds) * time.Second,
+		Timeout:     10 * time.Second,
+		BatchSize:   50,
+	}, time.Minute)
+	services.SetWebhookSender(webhookSender)
+
+	// Relay domain events from the outbox to notifications and webhooks
+	outboxRelay := services.NewOutboxRelay(db, services.OutboxRelayConfig{
+		MaxAttempts: cfg.OutboxMaxAttempts,
+		RetryDelay:  time.Duration(cfg.OutboxRetrySeconds) * time.Second,
+		BatchSize:   100,
+		Retention:   time.Duration(cfg.OutboxRetentionDays) * 24 * time.Hour,
+	}, time.Duration(cfg.OutboxPollSeconds)*time.Second,
+		services.NewNotificationConsumer(),
+		services.NewWebhookConsumer(),
+	)
+	services.SetOutboxRelay(outboxRelay)
+
+	// Start background jobs
+	go services.NewHandoverReminder(db, cfg.HandoverReminderDays, time.Hour).Start(context.Background())
+	go services.NewApprovalEscalator(db, services.EscalationPolicy{
+		SLADays:                    cfg.ApprovalSLADays,
+		ReminderDays:               cfg.ApprovalReminderDays,
+		AutoApproveDaysBeforeStart: cfg.AutoApproveDaysBeforeStart,
+	}, time.Hour).Start(context.Background())
+	go services.NewLeaveLifecycle(db, time.Hour).Start(context.Background())
+	go services.NewReminderScheduler(db, services.ReminderPolicy{
+		UpcomingDays:   cfg.UpcomingLeaveReminderDays,
+		NoticeDays:     cfg.LeaveNoticeDays,
+		NoticeLeadDays: cfg.LeaveNoticeLeadDays,
+	}, time.Hour).Start(context.Background())
+	go dispatcher.Start(context.Background())
+	go streamHub.Start(context.Background())
+	go webhookSender.Start(context.Background())
+	go outboxRelay.Start(context.Background())
+
 	// Setup Gin router without default middlewares
 	router := gin.New()
 	
@@ -40,7 +130,7 @@ func main() {
 		// Set CORS headers for all requests
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, Accept, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, Accept, X-Requested-With, Last-Event-ID")
 		c.Header("Access-Control-Expose-Headers", "Content-Length, Authorization")
 		c.Header("Access-Control-Max-Age", "43200")
 
@@ -68,6 +158,17 @@ func main() {
 		api.POST("/auth/login", handlers.Login(db))
 		api.POST("/auth/refresh", handlers.RefreshToken(db))
 
+		// Signed decision links from notifications, authenticated by the token itself
+		api.GET("/public/actions/:token", handlers.GetActionLink(db))
+		api.POST("/public/actions/:token", handlers.ConfirmActionLink(db))
+
+		// Slack slash command and message buttons, authenticated by the request signature
+		api.POST("/integrations/slack/commands", handlers.SlackCommand(db))
+		api.POST("/integrations/slack/interactions", handlers.SlackInteraction(db))
+
+		// Real-time stream; EventSource cannot send headers, so browsers pass a stream ticket in the query
+		api.GET("/notifications/stream", middleware.StreamAuth(cfg.JWTSecret), handlers.StreamEvents(db))
+
 		// Protected routes
 		protected := api.Group("/")
 		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
@@ -75,6 +176,8 @@ func main() {
 			// Auth routes
 			protected.POST("/auth/logout", handlers.Logout(db))
 			protected.GET("/auth/me", handlers.GetCurrentUser(db))
+			protected.GET("/auth/me/notification-preferences", handlers.GetNotificationPreferences(db))
+			protected.PUT("/auth/me/notification-preferences", handlers.UpdateNotificationPreferences(db))
 
 			// Vacation request routes
 			protected.GET("/vacation-requests", handlers.GetVacationRequests(db))
@@ -82,17 +185,61 @@ func main() {
 			protected.GET("/vacation-requests/stats", handlers.GetVacationRequestStats(db))
 			protected.GET("/vacation-requests/:id", handlers.GetVacationRequest(db))
 			protected.PUT("/vacation-requests/:id", handlers.UpdateVacationRequest(db))
+			protected.POST("/vacation-requests/:id/submit", handlers.SubmitVacationRequest(db))
+			protected.POST("/vacation-requests/:id/interrupt", handlers.InterruptVacationRequest(db))
 			protected.DELETE("/vacation-requests/:id", handlers.DeleteVacationRequest(db))
+			protected.POST("/vacation-requests/:id/substitute/accept", handlers.AcceptSubstitution(db))
+			protected.POST("/vacation-requests/:id/substitute/decline", handlers.DeclineSubstitution(db))
+			protected.GET("/vacation-requests/:id/handover", handlers.GetHandover(db))
+			protected.PUT("/vacation-requests/:id/handover", handlers.UpdateHandover(db))
+			protected.PATCH("/vacation-requests/:id/handover/items/:itemId", handlers.UpdateHandoverItem(db))
+			protected.GET("/vacation-requests/:id/comments", handlers.GetComments(db))
+			protected.POST("/vacation-requests/:id/comments", handlers.CreateComment(db))
+			protected.GET("/vacation-requests/:id/history", handlers.GetRequestHistory(db))
+
+			// Substitute routes
+			protected.GET("/substitute-requests", handlers.GetSubstituteRequests(db))
 
 			// Manager routes
 			protected.GET("/manager/pending-requests", handlers.GetPendingRequests(db))
 			protected.POST("/manager/approve/:id", handlers.ApproveVacationRequest(db))
 			protected.POST("/manager/reject/:id", handlers.RejectVacationRequest(db))
+			protected.POST("/manager/bulk-decision", handlers.BulkDecideVacationRequests(db))
 			protected.GET("/manager/team-calendar", handlers.GetTeamCalendar(db))
 			protected.GET("/manager/team-stats", handlers.GetTeamStats(db))
+			protected.POST("/manager/revoke/:id", handlers.RevokeAutoApproval(db))
+			protected.GET("/manager/auto-approval-rules", middleware.RequireRoles("manager", "admin"), handlers.GetAutoApprovalRules(db))
+			protected.POST("/manager/auto-approval-rules", middleware.RequireRoles("manager", "admin"), handlers.CreateAutoApprovalRule(db))
+			protected.PUT("/manager/auto-approval-rules/:id", middleware.RequireRoles("manager", "admin"), handlers.UpdateAutoApprovalRule(db))
+			protected.DELETE("/manager/auto-approval-rules/:id", middleware.RequireRoles("manager", "admin"), handlers.DeleteAutoApprovalRule(db))
+
+			// Org chart, visible to everyone
+			protected.GET("/org-chart", handlers.GetOrgChart(db))
+
+			// Team activity feed and holidays
+			protected.GET("/team/feed", handlers.GetTeamFeed(db))
+			protected.GET("/holidays", handlers.GetHolidays(db))
+			protected.POST("/holidays", middleware.RequireRole("admin"), handlers.CreateHoliday(db))
+			protected.DELETE("/holidays/:id", middleware.RequireRole("admin"), handlers.DeleteHoliday(db))
+
+			// Organization-wide routes (admin and HR)
+			org := protected.Group("/org")
+			org.Use(middleware.RequireRoles("admin", "hr"))
+			{
+				org.GET("/vacation-requests", handlers.GetOrgVacationRequests(db))
+				org.GET("/calendar", handlers.GetOrgCalendar(db))
+				org.GET("/stats", handlers.GetOrgStats(db))
+			}
+
+			// Approval delegation routes
+			protected.GET("/delegations", handlers.GetDelegations(db))
+			protected.POST("/delegations", handlers.CreateDelegation(db))
+			protected.DELETE("/delegations/:id", handlers.RevokeDelegation(db))
 
 			// Notification routes
+			protected.POST("/notifications/stream/ticket", handlers.CreateStreamTicket(cfg.JWTSecret))
 			protected.GET("/notifications", handlers.GetNotifications(db))
+			protected.GET("/notifications/unread-count", handlers.GetUnreadNotificationCount(db))
 			protected.PUT("/notifications/:id/read", handlers.MarkNotificationAsRead(db))
 			protected.PUT("/notifications/read-all", handlers.MarkAllNotificationsAsRead(db))
 
@@ -101,6 +248,27 @@ func main() {
 			protected.POST("/users", middleware.RequireRole("admin"), handlers.CreateUser(db))
 			protected.PUT("/users/:id", middleware.RequireRole("admin"), handlers.UpdateUser(db))
 			protected.DELETE("/users/:id", middleware.RequireRole("admin"), handlers.DeleteUser(db))
+
+			// Email template previews (admin only)
+			protected.GET("/email-templates", middleware.RequireRole("admin"), handlers.GetEmailTemplates(db))
+			protected.GET("/email-templates/:name/preview", middleware.RequireRole("admin"), handlers.PreviewEmailTemplate(db))
+
+			// Webhook subscription routes (admin only)
+			protected.GET("/webhooks", middleware.RequireRole("admin"), handlers.GetWebhooks(db))
+			protected.POST("/webhooks", middleware.RequireRole("admin"), handlers.CreateWebhook(db))
+			protected.PUT("/webhooks/:id", middleware.RequireRole("admin"), handlers.UpdateWebhook(db))
+			protected.DELETE("/webhooks/:id", middleware.RequireRole("admin"), handlers.DeleteWebhook(db))
+			protected.GET("/webhooks/:id/deliveries", middleware.RequireRole("admin"), handlers.GetWebhookDeliveries(db))
+			protected.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", middleware.RequireRole("admin"), handlers.RedeliverWebhook(db))
+
+			// Outbox metrics (admin only)
+			protected.GET("/outbox/metrics", middleware.RequireRole("admin"), handlers.GetOutboxMetrics(db))
+
+			// Approval rule routes (admin only)
+			protected.GET("/approval-rules", middleware.RequireRole("admin"), handlers.GetApprovalRules(db))
+			protected.POST("/approval-rules", middleware.RequireRole("admin"), handlers.CreateApprovalRule(db))
+			protected.PUT("/approval-rules/:id", middleware.RequireRole("admin"), handlers.UpdateApprovalRule(db))
+			protected.DELETE("/approval-rules/:id", middleware.RequireRole("admin"), handlers.DeleteApprovalRule(db))
 		}
 	}
 
@@ -0,0 +1,351 @@
+// Command slack-standin plays Slack locally to try the /ferias integration
+// without a workspace. It serves the users.info and views.open methods the
+// API calls to map users by email and to ask for rejection reasons, receives
+// the messages posted to response URLs, and sends signed slash commands,
+// button clicks and modal submissions typed on standard input.
+//
+// Start the API with SLACK_SIGNING_SECRET set to the same secret and
+// SLACK_API_URL=http://localhost:9090/api, then run:
+//
+//	go run ./cmd/slack-standin -secret <secret>
+//
+// and type, for example:
+//
+//	U02JOAO /ferias saldo
+//	U01MARIA /ferias equipe
+//	U01MARIA click 1
+//	U01MARIA click 2
+//	U01MARIA motivo Período de fechamento do trimestre
+package main
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type button struct {
+	ActionID string
+	Value    string
+	Label    string
+}
+
+type message struct {
+	Text   string `json:"text"`
+	Blocks []struct {
+		Type string `json:"type"`
+		Text *struct {
+			Text string `json:"text"`
+		} `json:"text"`
+		Elements []struct {
+			Text *struct {
+				Text string `json:"text"`
+			} `json:"text"`
+			ActionID string `json:"action_id"`
+			Value    string `json:"value"`
+		} `json:"elements"`
+	} `json:"blocks"`
+}
+
+// view is the modal last opened by the API
+type view struct {
+	CallbackID      string `json:"callback_id"`
+	PrivateMetadata string `json:"private_metadata"`
+	Title           struct {
+		Text string `json:"text"`
+	} `json:"title"`
+	Blocks []struct {
+		BlockID string `json:"block_id"`
+		Label   *struct {
+			Text string `json:"text"`
+		} `json:"label"`
+		Element *struct {
+			ActionID string `json:"action_id"`
+		} `json:"element"`
+	} `json:"blocks"`
+}
+
+type standIn struct {
+	apiURL    string
+	publicURL string
+	secret    string
+	team      string
+	users     map[string]string
+
+	mu      sync.Mutex
+	buttons []button
+	view    *view
+}
+
+func main() {
+	addr := flag.String("addr", ":9090", "address the stand-in listens on")
+	publicURL := flag.String("public-url", "http://localhost:9090", "URL the API reaches the stand-in at")
+	apiURL := flag.String("api", "http://localhost:8080/api/integrations/slack", "Slack integration endpoints of the API")
+	secret := flag.String("secret", os.Getenv("SLACK_SIGNING_SECRET"), "signing secret shared with the API")
+	team := flag.String("team", "T0STANDIN", "workspace ID sent with every request")
+	users := flag.String("users", "U00ADMIN=admin@empresa.com,U01MARIA=maria.silva@empresa.com,U02JOAO=joao.santos@empresa.com,U03ANA=ana.oliveira@empresa.com,U04CARLOS=carlos.pereira@empresa.com,U05PAULA=paula.costa@empresa.com",
+		"Slack user IDs and their emails")
+	flag.Parse()
+
+	if *secret == "" {
+		log.Fatal("A signing secret is required (-secret or SLACK_SIGNING_SECRET)")
+	}
+
+	s := &standIn{
+		apiURL:    strings.TrimRight(*apiURL, "/"),
+		publicURL: strings.TrimRight(*publicURL, "/"),
+		secret:    *secret,
+		team:      *team,
+		users:     map[string]string{},
+	}
+	for _, pair := range strings.Split(*users, ",") {
+		if id, email, ok := strings.Cut(strings.TrimSpace(pair), "="); ok {
+			s.users[id] = email
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/users.info", s.usersInfo)
+	mux.HandleFunc("/api/views.open", s.viewsOpen)
+	mux.HandleFunc("/response", s.response)
+	go func() {
+		log.Fatal(http.ListenAndServe(*addr, mux))
+	}()
+
+	fmt.Printf("Slack stand-in listening on %s\n", *addr)
+	for id, email := range s.users {
+		fmt.Printf("  %s = %s\n", id, email)
+	}
+	fmt.Println("Type \"<user> /ferias <text>\", \"<user> click <n>\" or \"<user> motivo <text>\".")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var err error
+		switch {
+		case fields[1] == "/ferias":
+			err = s.command(fields[0], strings.Join(fields[2:], " "))
+		case fields[1] == "click" && len(fields) == 3:
+			err = s.click(fields[0], fields[2])
+		case fields[1] == "motivo":
+			err = s.submit(fields[0], strings.Join(fields[2:], " "))
+		default:
+			err = fmt.Errorf("unknown input, type \"<user> /ferias <text>\", \"<user> click <n>\" or \"<user> motivo <text>\"")
+		}
+		if err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+}
+
+// usersInfo answers like Slack's users.info method
+func (s *standIn) usersInfo(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("user")
+	w.Header().Set("Content-Type", "application/json")
+
+	email, ok := s.users[id]
+	if !ok {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "user_not_found"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok": true,
+		"user": map[string]interface{}{
+			"id":      id,
+			"profile": map[string]string{"email": email},
+		},
+	})
+}
+
+// viewsOpen answers like Slack's views.open method and shows the modal
+func (s *standIn) viewsOpen(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		View view `json:"view"`
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "invalid_arguments"})
+		return
+	}
+
+	s.mu.Lock()
+	s.view = &body.View
+	s.mu.Unlock()
+
+	fmt.Printf("\n[modal] %s\n", body.View.Title.Text)
+	for _, block := range body.View.Blocks {
+		if block.Label != nil {
+			fmt.Printf("  %s (type \"<user> motivo <text>\")\n", block.Label.Text)
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+}
+
+// response prints the messages the API posts to response URLs
+func (s *standIn) response(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		fmt.Printf("\n[response] %s\n", body)
+		return
+	}
+	fmt.Println("\n[response]")
+	s.print(&msg)
+}
+
+func (s *standIn) command(user, text string) error {
+	form := url.Values{
+		"command":      {"/ferias"},
+		"text":         {text},
+		"team_id":      {s.team},
+		"user_id":      {user},
+		"response_url": {s.publicURL + "/response"},
+	}
+	body, err := s.post("/commands", form)
+	if err != nil {
+		return err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return fmt.Errorf("unexpected reply: %s", body)
+	}
+	s.print(&msg)
+	return nil
+}
+
+func (s *standIn) click(user, number string) error {
+	n, err := strconv.Atoi(number)
+	s.mu.Lock()
+	if err != nil || n < 1 || n > len(s.buttons) {
+		s.mu.Unlock()
+		return fmt.Errorf("no button %s in the last reply", number)
+	}
+	b := s.buttons[n-1]
+	s.mu.Unlock()
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"type":         "block_actions",
+		"trigger_id":   strconv.FormatInt(time.Now().UnixNano(), 10),
+		"team":         map[string]string{"id": s.team},
+		"user":         map[string]string{"id": user},
+		"response_url": s.publicURL + "/response",
+		"actions":      []map[string]string{{"action_id": b.ActionID, "value": b.Value}},
+	})
+	_, err = s.post("/interactions", url.Values{"payload": {string(payload)}})
+	return err
+}
+
+// submit fills the input of the open modal and submits it
+func (s *standIn) submit(user, text string) error {
+	s.mu.Lock()
+	v := s.view
+	s.mu.Unlock()
+	if v == nil {
+		return fmt.Errorf("no modal is open")
+	}
+
+	values := map[string]map[string]interface{}{}
+	for _, block := range v.Blocks {
+		if block.Element != nil {
+			values[block.BlockID] = map[string]interface{}{
+				block.Element.ActionID: map[string]string{"type": "plain_text_input", "value": text},
+			}
+		}
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"type": "view_submission",
+		"team": map[string]string{"id": s.team},
+		"user": map[string]string{"id": user},
+		"view": map[string]interface{}{
+			"callback_id":      v.CallbackID,
+			"private_metadata": v.PrivateMetadata,
+			"state":            map[string]interface{}{"values": values},
+		},
+	})
+	reply, err := s.post("/interactions", url.Values{"payload": {string(payload)}})
+	if err != nil {
+		return err
+	}
+
+	// Validation errors keep the modal open
+	if len(strings.TrimSpace(string(reply))) > 0 {
+		fmt.Printf("[modal] %s\n", reply)
+		return nil
+	}
+	s.mu.Lock()
+	s.view = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// post sends a form signed the way Slack signs requests
+func (s *standIn) post(path string, form url.Values) ([]byte, error) {
+	body := form.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req, err := http.NewRequest(http.MethodPost, s.apiURL+path, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	reply, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API responded with status %d: %s", resp.StatusCode, reply)
+	}
+	return reply, nil
+}
+
+// print shows a message, numbering its buttons for the click input
+func (s *standIn) print(msg *message) {
+	if len(msg.Blocks) == 0 {
+		fmt.Println(msg.Text)
+		return
+	}
+
+	var buttons []button
+	for _, block := range msg.Blocks {
+		if block.Text != nil {
+			fmt.Println(block.Text.Text)
+		}
+		for _, element := range block.Elements {
+			b := button{ActionID: element.ActionID, Value: element.Value}
+			if element.Text != nil {
+				b.Label = element.Text.Text
+			}
+			buttons = append(buttons, b)
+			fmt.Printf("  [%d] %s\n", len(buttons), b.Label)
+		}
+	}
+
+	s.mu.Lock()
+	s.buttons = buttons
+	s.mu.Unlock()
+}
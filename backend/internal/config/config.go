@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +14,72 @@ type Config struct {
 	JWTSecret   string
 	Port        string
 	GinMode     string
+
+	// Days before the start date to remind requesters with an empty handover
+	HandoverReminderDays int
+
+	// Days before the start date to remind requesters of an approved leave (0 disables)
+	UpcomingLeaveReminderDays int
+	// Days before the start the vacation notice is due, and how early HR is warned (0 disables)
+	LeaveNoticeDays     int
+	LeaveNoticeLeadDays int
+
+	// Business days an approver has before a pending request is escalated
+	ApprovalSLADays int
+	// Business-day thresholds at which approvers are reminded before escalation
+	ApprovalReminderDays []int
+	// Still-pending requests are auto-approved this many days before they start (0 disables)
+	AutoApproveDaysBeforeStart int
+
+	// Public URL of the signed approve/reject links sent to approvers (empty disables)
+	ActionLinkBaseURL string
+	// Hours a decision link stays valid
+	ActionLinkTTLHours int
+
+	// Channels notifications are delivered through besides the in-app center
+	NotificationChannels []string
+	// Per-type channel overrides, e.g. "reminder=email;comment="
+	NotificationEventChannels map[string][]string
+	// Attempts per channel before a delivery is given up
+	NotificationMaxAttempts int
+	// Seconds before the first retry, doubled on each further one
+	NotificationRetrySeconds int
+	// URL receiving notifications on the webhook channel (empty disables)
+	NotificationWebhookURL string
+
+	// Attempts per outbound webhook delivery before it is failed
+	WebhookMaxAttempts int
+	// Seconds before the first webhook retry, doubled on each further one
+	WebhookRetrySeconds int
+
+	// Seconds between outbox relay runs
+	OutboxPollSeconds int
+	// Attempts to relay an outbox event before it is failed
+	OutboxMaxAttempts int
+	// Seconds before the first outbox retry, doubled on each further one
+	OutboxRetrySeconds int
+	// Days published outbox events are kept
+	OutboxRetentionDays int
+
+	// Hours real-time events are kept for reconnecting clients to resume
+	StreamRetentionHours int
+
+	// Frontend URL linked from emails
+	AppBaseURL string
+
+	// SMTP server of the email channel (empty host disables)
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Slack signing secret of the /ferias command (empty disables)
+	SlackSigningSecret string
+	// Bot token used to read the email of Slack users
+	SlackBotToken string
+	// Slack Web API base URL, pointed at the stand-in server in development
+	SlackAPIURL string
 }
 
 func Load() *Config {
@@ -25,6 +93,47 @@ func Load() *Config {
 		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 		Port:        getEnv("PORT", "8080"),
 		GinMode:     getEnv("GIN_MODE", "debug"),
+
+		HandoverReminderDays: getEnvInt("HANDOVER_REMINDER_DAYS", 3),
+
+		UpcomingLeaveReminderDays: getEnvInt("UPCOMING_LEAVE_REMINDER_DAYS", 7),
+		LeaveNoticeDays:           getEnvInt("LEAVE_NOTICE_DAYS", 30),
+		LeaveNoticeLeadDays:       getEnvInt("LEAVE_NOTICE_LEAD_DAYS", 5),
+
+		ApprovalSLADays:            getEnvInt("APPROVAL_SLA_DAYS", 3),
+		ApprovalReminderDays:       getEnvIntList("APPROVAL_REMINDER_DAYS", []int{1, 2}),
+		AutoApproveDaysBeforeStart: getEnvInt("AUTO_APPROVE_DAYS_BEFORE_START", 0),
+
+		ActionLinkBaseURL:  getEnv("ACTION_LINK_BASE_URL", "http://localhost:8080/api/public/actions"),
+		ActionLinkTTLHours: getEnvInt("ACTION_LINK_TTL_HOURS", 72),
+
+		NotificationChannels:      getEnvList("NOTIFICATION_CHANNELS", []string{"email"}),
+		NotificationEventChannels: getEnvListMap("NOTIFICATION_EVENT_CHANNELS"),
+		NotificationMaxAttempts:   getEnvInt("NOTIFICATION_MAX_ATTEMPTS", 5),
+		NotificationRetrySeconds:  getEnvInt("NOTIFICATION_RETRY_SECONDS", 60),
+		NotificationWebhookURL:    getEnv("NOTIFICATION_WEBHOOK_URL", ""),
+
+		WebhookMaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookRetrySeconds: getEnvInt("WEBHOOK_RETRY_SECONDS", 30),
+
+		OutboxPollSeconds:   getEnvInt("OUTBOX_POLL_SECONDS", 2),
+		OutboxMaxAttempts:   getEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
+		OutboxRetrySeconds:  getEnvInt("OUTBOX_RETRY_SECONDS", 30),
+		OutboxRetentionDays: getEnvInt("OUTBOX_RETENTION_DAYS", 7),
+
+		StreamRetentionHours: getEnvInt("STREAM_RETENTION_HOURS", 24),
+
+		AppBaseURL: getEnv("APP_BASE_URL", "http://localhost:3000"),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "ferias@localhost"),
+
+		SlackSigningSecret: getEnv("SLACK_SIGNING_SECRET", ""),
+		SlackBotToken:      getEnv("SLACK_BOT_TOKEN", ""),
+		SlackAPIURL:        getEnv("SLACK_API_URL", "https://slack.com/api"),
 	}
 }
 
@@ -33,4 +142,67 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value for %s, using default %d", key, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvIntList(key string, defaultValue []int) []int {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+	var parsed []int
+	for _, part := range strings.Split(value, ",") {
+		number, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			log.Printf("Invalid value for %s, using default %v", key, defaultValue)
+			return defaultValue
+		}
+		parsed = append(parsed, number)
+	}
+	return parsed
+}
+
+func getEnvList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	return splitList(value, ",")
+}
+
+// getEnvListMap parses "key=a,b;other=c". A key with no values maps to an
+// empty list.
+func getEnvListMap(key string) map[string][]string {
+	parsed := map[string][]string{}
+	for _, entry := range splitList(os.Getenv(key), ";") {
+		name, values, found := strings.Cut(entry, "=")
+		if !found {
+			log.Printf("Invalid entry %q in %s, ignoring it", entry, key)
+			continue
+		}
+		parsed[strings.TrimSpace(name)] = splitList(values, ",")
+	}
+	return parsed
+}
+
+func splitList(value, separator string) []string {
+	parsed := []string{}
+	for _, part := range strings.Split(value, separator) {
+		if part = strings.TrimSpace(part); part != "" {
+			parsed = append(parsed, part)
+		}
+	}
+	return parsed
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/gerenciador-ferias/backend/internal/models"
 	"gorm.io/driver/postgres"
@@ -43,10 +44,58 @@ func Migrate(db *gorm.DB) error {
 		&models.User{},
 		&models.VacationRequest{},
 		&models.Notification{},
+		&models.Handover{},
+		&models.HandoverItem{},
+		&models.ApprovalRule{},
+		&models.ApprovalRuleStep{},
+		&models.ApprovalStep{},
+		&models.ApprovalDelegation{},
+		&models.AutoApprovalRule{},
+		&models.RequestComment{},
+		&models.CommentMention{},
+		&models.RequestHistory{},
+		&models.ActionToken{},
+		&models.NotificationDelivery{},
+		&models.NotificationChannelPreference{},
+		&models.StreamEvent{},
+		&models.ReminderLog{},
+		&models.NotificationSettings{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.OutboxEvent{},
+		&models.OutboxConsumption{},
+		&models.ChatIdentity{},
+		&models.Holiday{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	// Requests created before approval chains existed get a single manager step
+	if err := db.Exec(`
+		INSERT INTO approval_steps (id, vacation_request_id, position, approver_type, approver_id, approver_role, status, created_at, updated_at)
+		SELECT gen_random_uuid(), vr.id, 1, ?, u.manager_id, CASE WHEN u.manager_id IS NULL THEN ? ELSE '' END, ?, NOW(), NOW()
+		FROM vacation_requests vr
+		JOIN users u ON u.id = vr.user_id
+		WHERE vr.status = ? AND vr.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM approval_steps s WHERE s.vacation_request_id = vr.id)`,
+		models.ApproverManager, models.RoleHR, models.StepPending, models.StatusPending).Error; err != nil {
+		return fmt.Errorf("failed to backfill approval steps: %w", err)
+	}
+
+	// Older requests start their timeline with what is still known about them
+	if err := db.Exec(`
+		INSERT INTO request_history (id, vacation_request_id, actor_id, action, from_status, to_status, changes, comment, created_at)
+		SELECT gen_random_uuid(), vr.id, vr.user_id, ?, '', ?, '{}', '', vr.created_at
+		FROM vacation_requests vr
+		WHERE NOT EXISTS (SELECT 1 FROM request_history h WHERE h.vacation_request_id = vr.id)`,
+		models.HistoryCreated, models.StatusPending).Error; err != nil {
+		return fmt.Errorf("failed to backfill request history: %w", err)
+	}
+
+	if err := migrateOverlapConstraint(db); err != nil {
+		return err
+	}
+
 	log.Println("Database migration completed successfully")
 
 	// Seed database with initial data
@@ -55,4 +104,56 @@ func Migrate(db *gorm.DB) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// migrateOverlapConstraint makes PostgreSQL refuse two reserved requests of the
+// same employee sharing a day, which application checks alone cannot
+// guarantee under concurrent submissions
+func migrateOverlapConstraint(db *gorm.DB) error {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS btree_gist").Error; err != nil {
+		return fmt.Errorf("failed to create btree_gist extension: %w", err)
+	}
+
+	var existing int64
+	if err := db.Raw("SELECT COUNT(*) FROM pg_constraint WHERE conname = ?", models.OverlapConstraint).
+		Scan(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check overlap constraint: %w", err)
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	statuses := make([]string, len(models.ReservedStatuses))
+	for i, status := range models.ReservedStatuses {
+		statuses[i] = fmt.Sprintf("'%s'", status)
+	}
+
+	// Overlaps recorded before the constraint existed have to be resolved by
+	// hand; the API does not start without the constraint
+	var conflicts []string
+	if err := db.Raw(fmt.Sprintf(`
+		SELECT a.id::text || ' / ' || b.id::text FROM vacation_requests a
+		JOIN vacation_requests b ON b.user_id = a.user_id AND a.id::text < b.id::text
+		AND (a.start_date AT TIME ZONE 'UTC')::date <= (b.end_date AT TIME ZONE 'UTC')::date
+		AND (b.start_date AT TIME ZONE 'UTC')::date <= (a.end_date AT TIME ZONE 'UTC')::date
+		WHERE a.status IN (%[1]s) AND b.status IN (%[1]s) AND a.deleted_at IS NULL AND b.deleted_at IS NULL
+		ORDER BY 1`, strings.Join(statuses, ", "))).Scan(&conflicts).Error; err != nil {
+		return fmt.Errorf("failed to check overlapping requests: %w", err)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("failed to create overlap constraint: overlapping vacation requests must be cancelled or rejected first: %s",
+			strings.Join(conflicts, ", "))
+	}
+
+	// Days are compared in UTC so the range expression stays immutable
+	if err := db.Exec(fmt.Sprintf(`
+		ALTER TABLE vacation_requests ADD CONSTRAINT %s EXCLUDE USING gist (
+			user_id WITH =,
+			daterange((start_date AT TIME ZONE 'UTC')::date, (end_date AT TIME ZONE 'UTC')::date, '[]') WITH &&
+		) WHERE (status IN (%s) AND deleted_at IS NULL)`,
+		models.OverlapConstraint, strings.Join(statuses, ", "))).Error; err != nil {
+		return fmt.Errorf("failed to create overlap constraint: %w", err)
+	}
+
+	return nil
+}
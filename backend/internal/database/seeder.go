@@ -42,6 +42,26 @@ func SeedDatabase(db *gorm.DB) error {
 		return err
 	}
 
+	// Create HR user
+	hrPassword, err := utils.HashPassword("rh123456")
+	if err != nil {
+		return err
+	}
+
+	hr := models.User{
+		Email:           "paula.costa@empresa.com",
+		Name:            "Paula Costa",
+		PasswordHash:    hrPassword,
+		Role:            models.RoleHR,
+		VacationBalance: 30,
+		Department:      "RH",
+		Active:          true,
+	}
+
+	if err := db.Create(&hr).Error; err != nil {
+		return err
+	}
+
 	// Create manager user
 	managerPassword, err := utils.HashPassword("manager123")
 	if err != nil {
@@ -107,9 +127,27 @@ func SeedDatabase(db *gorm.DB) error {
 		}
 	}
 
+	// Long vacations also need HR sign-off
+	longVacationRule := models.ApprovalRule{
+		Name:            "Férias longas",
+		Priority:        10,
+		Active:          true,
+		MinBusinessDays: 15,
+		LeaveType:       models.LeaveTypeVacation,
+		Steps: []models.ApprovalRuleStep{
+			{Position: 1, ApproverType: models.ApproverManager},
+			{Position: 2, ApproverType: models.ApproverRole, ApproverRole: models.RoleHR},
+		},
+	}
+
+	if err := db.Create(&longVacationRule).Error; err != nil {
+		return err
+	}
+
 	log.Println("Database seeded successfully!")
 	log.Println("Available users:")
 	log.Println("- Admin: admin@empresa.com / admin123")
+	log.Println("- HR: paula.costa@empresa.com / rh123456")
 	log.Println("- Manager: maria.silva@empresa.com / manager123")
 	log.Println("- Employee: joao.santos@empresa.com / 123456")
 	log.Println("- Employee: ana.oliveira@empresa.com / 123456")
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// canViewRequest reports whether the user is the requester, the substitute,
+// the requester's manager, HR or an admin. The request must have User loaded.
+func canViewRequest(vacationRequest *models.VacationRequest, userID uuid.UUID, userRole string) bool {
+	if userRole == string(models.RoleAdmin) || userRole == string(models.RoleHR) || vacationRequest.UserID == userID {
+		return true
+	}
+	if vacationRequest.SubstituteID != nil && *vacationRequest.SubstituteID == userID {
+		return true
+	}
+	return vacationRequest.User.ManagerID != nil && *vacationRequest.User.ManagerID == userID
+}
+
+// isRequestApprover extends canViewRequest with the approvers of the chain
+// and the delegates currently deciding for them
+func isRequestApprover(db *gorm.DB, vacationRequest *models.VacationRequest, userID uuid.UUID, userRole string) (bool, error) {
+	if canViewRequest(vacationRequest, userID, userRole) {
+		return true, nil
+	}
+
+	var count int64
+	if err := db.Model(&models.ApprovalStep{}).
+		Where("vacation_request_id = ? AND (approver_id = ? OR on_behalf_of = ? OR decided_by = ?)",
+			vacationRequest.ID, userID, userID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	delegators, err := services.ActiveDelegators(db, userID, time.Now())
+	if err != nil || len(delegators) == 0 {
+		return false, err
+	}
+	if err := db.Model(&models.ApprovalStep{}).
+		Where("vacation_request_id = ? AND approver_id IN ?", vacationRequest.ID, delegators).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// isRequestParticipant extends isRequestApprover with managers above the
+// requester and the people mentioned in the comment thread
+func isRequestParticipant(db *gorm.DB, vacationRequest *models.VacationRequest, userID uuid.UUID, userRole string) (bool, error) {
+	approver, err := isRequestApprover(db, vacationRequest, userID, userRole)
+	if err != nil || approver {
+		return approver, err
+	}
+
+	// Managers further up see the requests of their whole subtree
+	inSubtree, err := services.IsInSubtree(db, userID, vacationRequest.UserID)
+	if err != nil || inSubtree {
+		return inSubtree, err
+	}
+
+	var count int64
+	if err := db.Model(&models.CommentMention{}).
+		Joins("JOIN request_comments ON request_comments.id = comment_mentions.comment_id").
+		Where("request_comments.vacation_request_id = ? AND comment_mentions.user_id = ?", vacationRequest.ID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
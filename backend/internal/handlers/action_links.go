@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// actionPageTemplate renders the pages opened from decision links. Deciding
+// takes a POST from the confirmation form, so link previews and mail
+// scanners fetching the URL never apply a decision.
+var actionPageTemplate = template.Must(template.New("action").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 32rem; margin: 3rem auto; padding: 0 1rem; color: #1f2937; }
+dl { display: grid; grid-template-columns: auto 1fr; gap: .25rem 1rem; }
+dt { font-weight: bold; }
+textarea { width: 100%; min-height: 5rem; margin: .5rem 0 1rem; }
+.error { color: #b91c1c; }
+button { padding: .6rem 1.2rem; border: 0; border-radius: .3rem; color: #fff; background: {{if eq .Action "approve"}}#15803d{{else}}#b91c1c{{end}}; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+{{with .Request}}
+<dl>
+<dt>Colaborador(a)</dt><dd>{{.User.Name}}</dd>
+<dt>Período</dt><dd>{{.StartDate.Format "02/01/2006"}} a {{.EndDate.Format "02/01/2006"}}</dd>
+<dt>Dias úteis</dt><dd>{{.BusinessDays}}</dd>
+{{if .Reason}}<dt>Motivo</dt><dd>{{.Reason}}</dd>{{end}}
+</dl>
+{{end}}
+{{if .Confirm}}
+<form method="post">
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{if eq .Action "approve"}}
+<label for="comment">Comentário (opcional)</label>
+<textarea id="comment" name="comment"></textarea>
+{{else}}
+<label for="comment">Motivo da rejeição</label>
+<textarea id="comment" name="comment" required></textarea>
+{{end}}
+<button type="submit">{{if eq .Action "approve"}}Confirmar aprovação{{else}}Confirmar rejeição{{end}}</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+type actionPage struct {
+	Title   string
+	Message string
+	Action  models.TokenAction
+	Request *models.VacationRequest
+	Confirm bool
+	Error   string
+}
+
+func renderActionPage(c *gin.Context, status int, page actionPage) {
+	var body bytes.Buffer
+	if err := actionPageTemplate.Execute(&body, page); err != nil {
+		log.Printf("Failed to render action page: %v", err)
+		c.String(http.StatusInternalServerError, "Erro ao processar a solicitação")
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Data(status, "text/html; charset=utf-8", body.Bytes())
+}
+
+// renderActionError shows why a link cannot be used
+func renderActionError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrInvalidActionToken, services.ErrRequestNotFound:
+		renderActionPage(c, http.StatusNotFound, actionPage{
+			Title:   "Link inválido",
+			Message: "Este link de aprovação não é válido. Acesse o sistema para decidir a solicitação.",
+		})
+	case services.ErrActionTokenExpired:
+		renderActionPage(c, http.StatusGone, actionPage{
+			Title:   "Link expirado",
+			Message: "Este link de aprovação expirou. Acesse o sistema para decidir a solicitação.",
+		})
+	case services.ErrInsufficientBalance:
+		renderActionPage(c, http.StatusConflict, actionPage{
+			Title:   "Saldo insuficiente",
+			Message: "O(a) colaborador(a) não tem mais saldo de férias suficiente para esta solicitação.",
+		})
+	case services.ErrActionTokenUsed, services.ErrRequestNotPending, services.ErrNotApprover, services.ErrRequestChanged:
+		renderActionPage(c, http.StatusConflict, actionPage{
+			Title:   "Solicitação já decidida",
+			Message: "Esta solicitação não aguarda mais a sua decisão.",
+		})
+	default:
+		renderActionPage(c, http.StatusInternalServerError, actionPage{
+			Title:   "Erro",
+			Message: "Não foi possível processar a solicitação. Tente novamente mais tarde.",
+		})
+	}
+}
+
+// confirmationPage loads the request of a link token and builds the page
+// asking to confirm its decision
+func confirmationPage(db *gorm.DB, token *models.ActionToken) (*actionPage, error) {
+	var vacationRequest models.VacationRequest
+	if err := db.Preload("User").Preload("ApprovalSteps", orderedSteps).
+		Where("id = ?", token.VacationRequestID).First(&vacationRequest).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, services.ErrRequestNotFound
+		}
+		return nil, err
+	}
+
+	// The step may have been decided in the app since the link was sent
+	step := services.CurrentApprovalStep(vacationRequest.ApprovalSteps)
+	if vacationRequest.Status != models.StatusPending || step == nil || step.ID != token.ApprovalStepID {
+		return nil, services.ErrRequestNotPending
+	}
+
+	page := &actionPage{
+		Title:   "Aprovar férias",
+		Message: "Confirme a aprovação da solicitação abaixo.",
+		Action:  token.Action,
+		Request: &vacationRequest,
+		Confirm: true,
+	}
+	if token.Action == models.TokenReject {
+		page.Title = "Rejeitar férias"
+		page.Message = "Informe o motivo e confirme a rejeição da solicitação abaixo."
+	}
+	return page, nil
+}
+
+func GetActionLink(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := services.ResolveActionToken(db, c.Param("token"))
+		if err != nil {
+			renderActionError(c, err)
+			return
+		}
+
+		page, err := confirmationPage(db, token)
+		if err != nil {
+			renderActionError(c, err)
+			return
+		}
+		renderActionPage(c, http.StatusOK, *page)
+	}
+}
+
+func ConfirmActionLink(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.ActionLinkDecisionRequest
+		if err := c.ShouldBind(&req); err != nil {
+			renderActionError(c, services.ErrInvalidActionToken)
+			return
+		}
+
+		result, err := services.DecideWithActionToken(db, c.Param("token"), req.Comment)
+		if err == services.ErrCommentRequired {
+			// Ask again, like the app does; the link is still unused
+			token, err := services.ResolveActionToken(db, c.Param("token"))
+			if err != nil {
+				renderActionError(c, err)
+				return
+			}
+			page, err := confirmationPage(db, token)
+			if err != nil {
+				renderActionError(c, err)
+				return
+			}
+			page.Error = "Informe o motivo da rejeição."
+			renderActionPage(c, http.StatusBadRequest, *page)
+			return
+		}
+		if err != nil {
+			renderActionError(c, err)
+			return
+		}
+
+		page := actionPage{
+			Title:   "Solicitação aprovada",
+			Message: "Sua aprovação foi registrada.",
+			Request: result.Request,
+		}
+		switch {
+		case result.Request.Status == models.StatusRejected:
+			page.Title = "Solicitação rejeitada"
+			page.Message = "Sua rejeição foi registrada e o(a) colaborador(a) será avisado(a)."
+		case !result.Final:
+			page.Message = "Sua aprovação foi registrada. A solicitação segue para a próxima etapa."
+		}
+		renderActionPage(c, http.StatusOK, page)
+	}
+}
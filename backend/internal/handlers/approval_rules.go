@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// buildRuleSteps validates the step inputs and converts them to rule steps
+func buildRuleSteps(db *gorm.DB, inputs []models.ApprovalRuleStepInput) ([]models.ApprovalRuleStep, string, error) {
+	var steps []models.ApprovalRuleStep
+	for i, input := range inputs {
+		step := models.ApprovalRuleStep{
+			Position:     i + 1,
+			ApproverType: input.ApproverType,
+		}
+
+		switch input.ApproverType {
+		case models.ApproverUser:
+			if input.ApproverUserID == nil {
+				return nil, "approver_user_id is required for user steps", nil
+			}
+			var count int64
+			if err := db.Model(&models.User{}).Where("id = ? AND active = ?", *input.ApproverUserID, true).Count(&count).Error; err != nil {
+				return nil, "", err
+			}
+			if count == 0 {
+				return nil, "Approver user not found", nil
+			}
+			step.ApproverUserID = input.ApproverUserID
+		case models.ApproverRole:
+			if input.ApproverRole == "" {
+				return nil, "approver_role is required for role steps", nil
+			}
+			step.ApproverRole = input.ApproverRole
+		}
+
+		steps = append(steps, step)
+	}
+	return steps, "", nil
+}
+
+func GetApprovalRules(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var rules []models.ApprovalRule
+		if err := db.Preload("Steps", orderedSteps).
+			Order("priority DESC, created_at ASC").
+			Find(&rules).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch approval rules",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"rules": rules,
+			"total": len(rules),
+		})
+	}
+}
+
+func CreateApprovalRule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.ApprovalRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		steps, message, err := buildRuleSteps(db, req.Steps)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to validate approval steps",
+			})
+			return
+		}
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		rule := models.ApprovalRule{
+			Name:            req.Name,
+			Priority:        req.Priority,
+			Active:          req.Active == nil || *req.Active,
+			MinBusinessDays: req.MinBusinessDays,
+			Department:      req.Department,
+			LeaveType:       req.LeaveType,
+			Steps:           steps,
+		}
+
+		if err := db.Create(&rule).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create approval rule",
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, rule)
+	}
+}
+
+func UpdateApprovalRule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ruleID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid rule ID format",
+			})
+			return
+		}
+
+		var req models.ApprovalRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		var rule models.ApprovalRule
+		if err := db.Where("id = ?", ruleID).First(&rule).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Approval rule not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch approval rule",
+			})
+			return
+		}
+
+		steps, message, err := buildRuleSteps(db, req.Steps)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to validate approval steps",
+			})
+			return
+		}
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		// Changes only affect requests created afterwards; existing chains are kept
+		rule.Name = req.Name
+		rule.Priority = req.Priority
+		if req.Active != nil {
+			rule.Active = *req.Active
+		}
+		rule.MinBusinessDays = req.MinBusinessDays
+		rule.Department = req.Department
+		rule.LeaveType = req.LeaveType
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Omit("Steps").Save(&rule).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("rule_id = ?", rule.ID).Delete(&models.ApprovalRuleStep{}).Error; err != nil {
+				return err
+			}
+			for i := range steps {
+				steps[i].RuleID = rule.ID
+			}
+			return tx.Create(&steps).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update approval rule",
+			})
+			return
+		}
+
+		rule.Steps = steps
+		c.JSON(http.StatusOK, rule)
+	}
+}
+
+func DeleteApprovalRule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ruleID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid rule ID format",
+			})
+			return
+		}
+
+		result := db.Where("id = ?", ruleID).Delete(&models.ApprovalRule{})
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to delete approval rule",
+			})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Approval rule not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Approval rule deleted successfully",
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// applyAutoApprovalRuleRequest copies the request fields onto the rule, keeping
+// the current values of omitted optional fields
+func applyAutoApprovalRuleRequest(rule *models.AutoApprovalRule, req *models.AutoApprovalRuleRequest) {
+	rule.Name = req.Name
+	rule.LeaveType = req.LeaveType
+	rule.MaxBusinessDays = req.MaxBusinessDays
+	rule.MinDaysInAdvance = req.MinDaysInAdvance
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	if req.RequireNoConflict != nil {
+		rule.RequireNoConflict = *req.RequireNoConflict
+	}
+	if req.RevokeWindowHours != nil {
+		rule.RevokeWindowHours = *req.RevokeWindowHours
+	}
+}
+
+func GetAutoApprovalRules(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		managerID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var rules []models.AutoApprovalRule
+		if err := db.Where("manager_id = ?", managerID).Order("created_at ASC").Find(&rules).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch auto-approval rules",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"rules": rules,
+			"total": len(rules),
+		})
+	}
+}
+
+func CreateAutoApprovalRule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		managerID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var req models.AutoApprovalRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		rule := models.AutoApprovalRule{
+			ManagerID:         managerID,
+			Active:            true,
+			RequireNoConflict: true,
+			RevokeWindowHours: 48,
+		}
+		applyAutoApprovalRuleRequest(&rule, &req)
+
+		if err := db.Create(&rule).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create auto-approval rule",
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, rule)
+	}
+}
+
+func UpdateAutoApprovalRule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		managerID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		ruleID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid rule ID format",
+			})
+			return
+		}
+
+		var req models.AutoApprovalRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		var rule models.AutoApprovalRule
+		if err := db.Where("id = ? AND manager_id = ?", ruleID, managerID).First(&rule).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Auto-approval rule not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch auto-approval rule",
+			})
+			return
+		}
+
+		applyAutoApprovalRuleRequest(&rule, &req)
+
+		if err := db.Save(&rule).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update auto-approval rule",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, rule)
+	}
+}
+
+func DeleteAutoApprovalRule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		managerID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		ruleID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid rule ID format",
+			})
+			return
+		}
+
+		result := db.Where("id = ? AND manager_id = ?", ruleID, managerID).Delete(&models.AutoApprovalRule{})
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to delete auto-approval rule",
+			})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Auto-approval rule not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Auto-approval rule deleted successfully",
+		})
+	}
+}
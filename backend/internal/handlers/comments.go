@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// loadCommentRequest fetches the request behind a thread and checks that the
+// user takes part in it. A nil request with a nil error means not found.
+func loadCommentRequest(db *gorm.DB, requestID, userID uuid.UUID, userRole string) (*models.VacationRequest, error) {
+	var vacationRequest models.VacationRequest
+	if err := db.Preload("User").Where("id = ?", requestID).First(&vacationRequest).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	allowed, err := isRequestParticipant(db, &vacationRequest, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, nil
+	}
+	return &vacationRequest, nil
+}
+
+func GetComments(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
+
+		requestID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		vacationRequest, err := loadCommentRequest(db, requestID, userID, userRoleStr)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vacation request",
+			})
+			return
+		}
+		if vacationRequest == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Vacation request not found",
+			})
+			return
+		}
+
+		var comments []models.RequestComment
+		if err := db.Preload("Author").Preload("Mentions.User").
+			Where("vacation_request_id = ?", requestID).
+			Order("created_at ASC").
+			Find(&comments).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch comments",
+			})
+			return
+		}
+
+		responseComments := []*models.CommentResponse{}
+		for i := range comments {
+			responseComments = append(responseComments, comments[i].ToResponse())
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"comments": responseComments,
+			"total":    len(responseComments),
+		})
+	}
+}
+
+func CreateComment(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
+
+		requestID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		var req models.CreateCommentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		vacationRequest, err := loadCommentRequest(db, requestID, userID, userRoleStr)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vacation request",
+			})
+			return
+		}
+		if vacationRequest == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Vacation request not found",
+			})
+			return
+		}
+
+		// Mentioned users must exist and are brought into the thread
+		comment := models.RequestComment{
+			VacationRequestID: requestID,
+			AuthorID:          userID,
+			Body:              req.Body,
+		}
+		seen := map[uuid.UUID]bool{}
+		for _, mentionID := range req.MentionIDs {
+			if mentionID == userID || seen[mentionID] {
+				continue
+			}
+			seen[mentionID] = true
+			comment.Mentions = append(comment.Mentions, models.CommentMention{UserID: mentionID})
+		}
+
+		if len(comment.Mentions) > 0 {
+			ids := make([]uuid.UUID, 0, len(comment.Mentions))
+			for _, mention := range comment.Mentions {
+				ids = append(ids, mention.UserID)
+			}
+			var mentionedCount int64
+			if err := db.Model(&models.User{}).Where("id IN ? AND active = ?", ids, true).Count(&mentionedCount).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to validate mentions",
+				})
+				return
+			}
+			if int(mentionedCount) != len(ids) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Mentioned user not found",
+				})
+				return
+			}
+		}
+
+		// The thread is part of the request timeline
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&comment).Error; err != nil {
+				return err
+			}
+			return services.RecordCommentHistory(tx, models.RequestHistory{
+				VacationRequestID: requestID,
+				ActorID:           &userID,
+				Action:            models.HistoryCommented,
+				FromStatus:        vacationRequest.Status,
+				ToStatus:          vacationRequest.Status,
+				Comment:           comment.Body,
+			}, comment.ID)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create comment",
+			})
+			return
+		}
+
+		if err := db.Preload("Author").Preload("Mentions.User").First(&comment, "id = ?", comment.ID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load comment details",
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, comment.ToResponse())
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func GetDelegations(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var given []models.ApprovalDelegation
+		if err := db.Preload("Delegate").Where("delegator_id = ?", userID).
+			Order("start_date DESC").Find(&given).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch delegations",
+			})
+			return
+		}
+
+		var received []models.ApprovalDelegation
+		if err := db.Preload("Delegator").Where("delegate_id = ?", userID).
+			Order("start_date DESC").Find(&received).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch delegations",
+			})
+			return
+		}
+
+		// Who currently decides for the caller, including automatic delegation during leave
+		activeDelegate, err := services.ActiveDelegate(db, userID, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to resolve active delegate",
+			})
+			return
+		}
+
+		givenResponse := []*models.DelegationResponse{}
+		for i := range given {
+			givenResponse = append(givenResponse, given[i].ToResponse())
+		}
+		receivedResponse := []*models.DelegationResponse{}
+		for i := range received {
+			receivedResponse = append(receivedResponse, received[i].ToResponse())
+		}
+
+		response := gin.H{
+			"given":    givenResponse,
+			"received": receivedResponse,
+		}
+		if activeDelegate != nil {
+			response["active_delegate_id"] = activeDelegate.String()
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func CreateDelegation(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var req models.CreateDelegationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		if req.EndDate.Before(req.StartDate) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "End date must be after start date",
+			})
+			return
+		}
+
+		if req.DelegateID == userID {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "You cannot delegate to yourself",
+			})
+			return
+		}
+
+		var delegate models.User
+		if err := db.Where("id = ? AND active = ?", req.DelegateID, true).First(&delegate).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Delegate not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch delegate",
+			})
+			return
+		}
+
+		// Only one delegate at a time
+		var overlapping int64
+		if err := db.Model(&models.ApprovalDelegation{}).
+			Where("delegator_id = ? AND revoked_at IS NULL AND start_date <= ? AND end_date >= ?",
+				userID, req.EndDate, req.StartDate).
+			Count(&overlapping).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check for overlapping delegations",
+			})
+			return
+		}
+
+		if overlapping > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "You already have a delegation during this period",
+			})
+			return
+		}
+
+		delegation := models.ApprovalDelegation{
+			DelegatorID: userID,
+			DelegateID:  req.DelegateID,
+			StartDate:   req.StartDate,
+			EndDate:     req.EndDate,
+			Reason:      req.Reason,
+		}
+
+		// The delegate hears about it through the outbox
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&delegation).Error; err != nil {
+				return err
+			}
+			return services.RecordEvent(tx, models.AggregateDelegation, delegation.ID,
+				models.EventDelegationCreated, struct{}{})
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create delegation",
+			})
+			return
+		}
+
+		if err := db.Preload("Delegator").Preload("Delegate").First(&delegation, delegation.ID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load delegation details",
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, delegation.ToResponse())
+	}
+}
+
+func RevokeDelegation(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		delegationID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid delegation ID format",
+			})
+			return
+		}
+
+		var delegation models.ApprovalDelegation
+		if err := db.Where("id = ? AND delegator_id = ? AND revoked_at IS NULL", delegationID, userID).
+			First(&delegation).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Delegation not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch delegation",
+			})
+			return
+		}
+
+		now := time.Now()
+		delegation.RevokedAt = &now
+		if err := db.Save(&delegation).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to revoke delegation",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Delegation revoked successfully",
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sampleVacationRequest fills the templates when no real request is previewed
+func sampleVacationRequest() *models.VacationRequest {
+	start := time.Now().AddDate(0, 1, 0).Truncate(24 * time.Hour)
+	return &models.VacationRequest{
+		ID:              uuid.New(),
+		User:            models.User{Name: "Maria Silva", Email: "maria.silva@example.com"},
+		StartDate:       start,
+		EndDate:         start.AddDate(0, 0, 13),
+		BusinessDays:    10,
+		Status:          models.StatusPending,
+		Reason:          "Viagem em família",
+		ApprovalComment: "Boas férias!",
+	}
+}
+
+func GetEmailTemplates(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"templates": models.EmailTemplates,
+			"locales":   services.EmailLocales,
+		})
+	}
+}
+
+// PreviewEmailTemplate renders a template as the signed-in admin would receive
+// it, with a real request when request_id is given and sample data otherwise
+func PreviewEmailTemplate(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var recipient models.User
+		if err := db.Where("id = ?", userID).First(&recipient).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch user",
+			})
+			return
+		}
+
+		request := sampleVacationRequest()
+		if value := c.Query("request_id"); value != "" {
+			requestID, err := uuid.Parse(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid request ID format",
+				})
+				return
+			}
+			request = &models.VacationRequest{}
+			if err := db.Preload("User").Where("id = ?", requestID).First(request).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					c.JSON(http.StatusNotFound, gin.H{
+						"error": "Vacation request not found",
+					})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to fetch vacation request",
+				})
+				return
+			}
+		}
+
+		name := c.Param("name")
+		data := &services.EmailData{
+			Recipient: &recipient,
+			Request:   request,
+			Title:     "Notificação de exemplo",
+			Message:   "Esta é uma notificação sem modelo próprio.\nEla é enviada com o título e a mensagem.",
+			Data:      map[string]string{},
+		}
+		switch name {
+		case models.TemplateRequestSubmitted, models.TemplateApprovalReminder, models.TemplateApprovalExpired:
+			data.Data["step"] = "1"
+			data.Data["approve_url"] = "https://example.com/actions/preview?action=approve"
+			data.Data["reject_url"] = "https://example.com/actions/preview?action=reject"
+		case models.TemplateDigest:
+			data.Data["frequency"] = string(models.DigestDaily)
+			data.Items = []models.Notification{
+				{Type: models.NotificationComment, Title: "Novo Comentário", Message: "Maria Silva comentou na solicitação de férias.", CreatedAt: time.Now().Add(-3 * time.Hour)},
+				{Type: models.NotificationReminder, Title: "Férias se Aproximando", Message: "Suas férias começam em 7 dia(s).", CreatedAt: time.Now().Add(-time.Hour)},
+			}
+		}
+
+		locale := c.DefaultQuery("locale", recipient.Locale)
+		email, err := services.RenderEmail(locale, name, data)
+		if err != nil {
+			if err == services.ErrUnknownEmailTemplate {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Email template not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to render email template",
+			})
+			return
+		}
+
+		switch c.DefaultQuery("format", "html") {
+		case "html":
+			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+		case "text":
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(email.Text))
+		case "json":
+			c.JSON(http.StatusOK, gin.H{
+				"locale":  services.EmailLocale(locale),
+				"subject": email.Subject,
+				"text":    email.Text,
+				"html":    email.HTML,
+			})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid format, use html, text or json",
+			})
+		}
+	}
+}
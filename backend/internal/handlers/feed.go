@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetTeamFeed lists what happens around the caller: approved leaves, leaves
+// starting and ending, upcoming returns and holidays. Requests still being
+// decided are left out, and so are reasons and emergency contacts.
+func GetTeamFeed(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		subtree, message := parseTeamScope(c)
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		var cursor *services.FeedCursor
+		if cursorStr := c.Query("cursor"); cursorStr != "" {
+			if cursor, err = services.ParseFeedCursor(cursorStr); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid cursor",
+				})
+				return
+			}
+		}
+
+		user, err := loadUser(db, userID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		items, next, err := services.TeamFeed(db, user, subtree, cursor, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch team feed",
+			})
+			return
+		}
+
+		response := models.FeedResponse{Items: items}
+		if response.Items == nil {
+			response.Items = []models.FeedItem{}
+		}
+		if next != nil {
+			response.NextCursor = next.Encode()
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
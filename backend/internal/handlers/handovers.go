@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// handoverEditable reports whether the requester may still change the handover
+func handoverEditable(vacationRequest *models.VacationRequest) bool {
+	if !vacationRequest.Editable() && vacationRequest.Status != models.StatusApproved {
+		return false
+	}
+	return time.Now().Before(vacationRequest.StartDate)
+}
+
+// loadHandover returns the stored handover of a request, or an empty unsaved one
+func loadHandover(db *gorm.DB, requestID uuid.UUID) (models.Handover, error) {
+	var handover models.Handover
+	err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Preload("Items.Owner").
+		Where("vacation_request_id = ?", requestID).
+		First(&handover).Error
+	if err == gorm.ErrRecordNotFound {
+		return models.Handover{VacationRequestID: requestID}, nil
+	}
+	return handover, err
+}
+
+func GetHandover(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
+
+		requestID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		var vacationRequest models.VacationRequest
+		if err := db.Preload("User").Where("id = ?", requestID).First(&vacationRequest).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Vacation request not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vacation request",
+			})
+			return
+		}
+
+		if !canViewRequest(&vacationRequest, userID, userRoleStr) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Vacation request not found",
+			})
+			return
+		}
+
+		handover, err := loadHandover(db, requestID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch handover",
+			})
+			return
+		}
+
+		editable := vacationRequest.UserID == userID && handoverEditable(&vacationRequest)
+		c.JSON(http.StatusOK, handover.ToResponse(editable))
+	}
+}
+
+func UpdateHandover(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		requestID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		var req models.UpdateHandoverRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		// Only the requester can write the handover
+		var vacationRequest models.VacationRequest
+		if err := db.Where("id = ? AND user_id = ?", requestID, userID).First(&vacationRequest).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Vacation request not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vacation request",
+			})
+			return
+		}
+
+		if !handoverEditable(&vacationRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Handover can only be edited for active requests before the start date",
+			})
+			return
+		}
+
+		// Make sure every owner is an existing active user
+		ownerIDs := map[uuid.UUID]bool{}
+		for _, item := range req.Items {
+			if item.OwnerID != nil {
+				ownerIDs[*item.OwnerID] = true
+			}
+		}
+		if len(ownerIDs) > 0 {
+			ids := make([]uuid.UUID, 0, len(ownerIDs))
+			for id := range ownerIDs {
+				ids = append(ids, id)
+			}
+			var ownersCount int64
+			if err := db.Model(&models.User{}).Where("id IN ? AND active = ?", ids, true).Count(&ownersCount).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to validate item owners",
+				})
+				return
+			}
+			if int(ownersCount) != len(ids) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Item owner not found",
+				})
+				return
+			}
+		}
+
+		handover, err := loadHandover(db, requestID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch handover",
+			})
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			handover.Notes = req.Notes
+			if err := tx.Omit("Items").Save(&handover).Error; err != nil {
+				return err
+			}
+
+			existing := map[uuid.UUID]models.HandoverItem{}
+			for _, item := range handover.Items {
+				existing[item.ID] = item
+			}
+
+			// Items sent with an ID keep their checklist state, the others are new
+			kept := map[uuid.UUID]bool{}
+			for position, input := range req.Items {
+				item := models.HandoverItem{HandoverID: handover.ID}
+				if input.ID != nil {
+					if current, ok := existing[*input.ID]; ok {
+						item = current
+						kept[item.ID] = true
+					}
+				}
+				item.Type = input.Type
+				if item.Type == "" {
+					item.Type = models.HandoverItemTask
+				}
+				item.Title = input.Title
+				item.Details = input.Details
+				item.URL = input.URL
+				item.OwnerID = input.OwnerID
+				item.Owner = nil
+				item.Position = position
+				if err := tx.Save(&item).Error; err != nil {
+					return err
+				}
+			}
+
+			for id := range existing {
+				if !kept[id] {
+					if err := tx.Delete(&models.HandoverItem{}, "id = ?", id).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update handover",
+			})
+			return
+		}
+
+		handover, err = loadHandover(db, requestID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load handover details",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, handover.ToResponse(true))
+	}
+}
+
+func UpdateHandoverItem(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
+
+		requestID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		itemID, err := uuid.Parse(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid item ID format",
+			})
+			return
+		}
+
+		var req models.UpdateHandoverItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		var vacationRequest models.VacationRequest
+		if err := db.Preload("User").Where("id = ?", requestID).First(&vacationRequest).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Vacation request not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vacation request",
+			})
+			return
+		}
+
+		if !canViewRequest(&vacationRequest, userID, userRoleStr) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Vacation request not found",
+			})
+			return
+		}
+
+		var item models.HandoverItem
+		if err := db.Joins("JOIN handovers ON handovers.id = handover_items.handover_id").
+			Where("handover_items.id = ? AND handovers.vacation_request_id = ?", itemID, requestID).
+			First(&item).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Handover item not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch handover item",
+			})
+			return
+		}
+
+		item.Completed = req.Completed
+		if req.Completed {
+			now := time.Now()
+			item.CompletedAt = &now
+			item.CompletedBy = &userID
+		} else {
+			item.CompletedAt = nil
+			item.CompletedBy = nil
+		}
+
+		if err := db.Save(&item).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update handover item",
+			})
+			return
+		}
+
+		if err := db.Preload("Owner").First(&item, item.ID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load handover item details",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, item.ToResponse())
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetRequestHistory returns the timeline of a request. Only those who can see
+// the request itself and its approvers read it, as it carries full user
+// details and the previous values of every field.
+func GetRequestHistory(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
+
+		requestID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		var vacationRequest models.VacationRequest
+		if err := db.Preload("User").Where("id = ?", requestID).First(&vacationRequest).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Vacation request not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vacation request",
+			})
+			return
+		}
+
+		allowed, err := isRequestApprover(db, &vacationRequest, userID, userRoleStr)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check access to vacation request",
+			})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Vacation request not found",
+			})
+			return
+		}
+
+		var history []models.RequestHistory
+		if err := db.Preload("Actor").Scopes(chronologicalHistory).
+			Where("vacation_request_id = ?", requestID).
+			Find(&history).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch request history",
+			})
+			return
+		}
+
+		entries := []*models.RequestHistoryResponse{}
+		for i := range history {
+			entries = append(entries, history[i].ToResponse())
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"vacation_request_id": requestID.String(),
+			"history":             entries,
+			"total":               len(entries),
+		})
+	}
+}
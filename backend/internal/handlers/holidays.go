@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func GetHolidays(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		year := time.Now().Year()
+		if yearStr := c.Query("year"); yearStr != "" {
+			parsed, err := strconv.Atoi(yearStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid year",
+				})
+				return
+			}
+			year = parsed
+		}
+
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		var holidays []models.Holiday
+		if err := db.Where("date >= ? AND date < ?", from, from.AddDate(1, 0, 0)).
+			Order("date ASC").Find(&holidays).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch holidays",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"holidays": holidays,
+			"total":    len(holidays),
+		})
+	}
+}
+
+func CreateHoliday(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.CreateHolidayRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		date := time.Date(req.Date.Year(), req.Date.Month(), req.Date.Day(), 0, 0, 0, 0, time.UTC)
+		var existing int64
+		if err := db.Model(&models.Holiday{}).Where("date = ?", date).Count(&existing).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check for existing holidays",
+			})
+			return
+		}
+		if existing > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "There is already a holiday on this date",
+			})
+			return
+		}
+
+		holiday := models.Holiday{
+			Date: date,
+			Name: req.Name,
+		}
+		if err := db.Create(&holiday).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create holiday",
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, holiday)
+	}
+}
+
+func DeleteHoliday(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		holidayID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid holiday ID format",
+			})
+			return
+		}
+
+		result := db.Where("id = ?", holidayID).Delete(&models.Holiday{})
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to delete holiday",
+			})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Holiday not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Holiday deleted successfully",
+		})
+	}
+}
@@ -7,11 +7,53 @@ import (
 
 	"github.com/gerenciador-ferias/backend/internal/middleware"
 	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// awaitingDecisionBy restricts a vacation request query to pending requests
+// whose current approval step is assigned to one of the given users or to the
+// role. Admins also see the steps assigned to HR.
+func awaitingDecisionBy(approverIDs []uuid.UUID, role string) func(db *gorm.DB) *gorm.DB {
+	roles := []string{role}
+	if role == string(models.RoleAdmin) {
+		roles = append(roles, string(models.RoleHR))
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Joins("JOIN approval_steps ON approval_steps.vacation_request_id = vacation_requests.id").
+			Where("vacation_requests.status = ? AND approval_steps.status = ?", models.StatusPending, models.StepPending).
+			Where("approval_steps.position = (SELECT MIN(s.position) FROM approval_steps s WHERE s.vacation_request_id = vacation_requests.id AND s.status = ?)", models.StepPending).
+			Where("approval_steps.approver_id IN ? OR (approval_steps.approver_id IS NULL AND approval_steps.approver_role IN ?)", approverIDs, roles)
+	}
+}
+
+// parseTeamScope reads the scope query parameter: "direct" (the default) for
+// direct reports only, "subtree" for everyone below the caller
+func parseTeamScope(c *gin.Context) (bool, string) {
+	switch c.DefaultQuery("scope", "direct") {
+	case "direct":
+		return false, ""
+	case "subtree":
+		return true, ""
+	default:
+		return false, "Invalid scope (direct or subtree)"
+	}
+}
+
+// teamMembersOf restricts a query on users, or joined with users, to the
+// manager's direct reports or to the whole subtree below the manager
+func teamMembersOf(managerID uuid.UUID, subtree bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if subtree {
+			return db.Where("users.id IN (?)", services.SubtreeQuery(db.Session(&gorm.Session{NewDB: true}), managerID))
+		}
+		return db.Where("users.manager_id = ?", managerID)
+	}
+}
+
 func GetPendingRequests(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userIDStr, exists := c.Get(middleware.UserIDKey)
@@ -22,13 +64,8 @@ func GetPendingRequests(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		userRole, exists := c.Get(middleware.UserRoleKey)
-		if !exists || (userRole != "manager" && userRole != "admin") {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-			})
-			return
-		}
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
 
 		managerID, err := uuid.Parse(userIDStr.(string))
 		if err != nil {
@@ -51,10 +88,38 @@ func GetPendingRequests(db *gorm.DB) gin.HandlerFunc {
 
 		offset := (page - 1) * perPage
 
-		// Get pending requests from team members
-		query := db.Preload("User").Preload("Approver").
-			Joins("JOIN users ON users.id = vacation_requests.user_id").
-			Where("users.manager_id = ? AND vacation_requests.status = ?", managerID, models.StatusPending)
+		subtree, message := parseTeamScope(c)
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		// Include the approvers the caller is currently standing in for
+		delegators, err := services.ActiveDelegators(db, managerID, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to resolve approval delegations",
+			})
+			return
+		}
+		approverIDs := append([]uuid.UUID{managerID}, delegators...)
+
+		// Get pending requests whose current approval step is assigned to the caller
+		query := db.Preload("User").Preload("Approver").Preload("Substitute").Preload("ApprovalSteps", orderedSteps).
+			Preload("History", chronologicalHistory).Preload("History.Actor").
+			Where("vacation_requests.user_id <> ?", managerID)
+		if subtree {
+			// Skip-level view: also show what is pending anywhere below the caller,
+			// even when another manager decides it
+			awaiting := db.Model(&models.VacationRequest{}).Select("vacation_requests.id").
+				Scopes(awaitingDecisionBy(approverIDs, userRoleStr))
+			query = query.Where("vacation_requests.id IN (?) OR (vacation_requests.status = ? AND vacation_requests.user_id IN (?))",
+				awaiting, models.StatusPending, services.SubtreeQuery(db, managerID))
+		} else {
+			query = query.Scopes(awaitingDecisionBy(approverIDs, userRoleStr))
+		}
 
 		// Count total
 		var total int64
@@ -105,13 +170,8 @@ func ApproveVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		userRole, exists := c.Get(middleware.UserRoleKey)
-		if !exists || (userRole != "manager" && userRole != "admin") {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-			})
-			return
-		}
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
 
 		managerID, err := uuid.Parse(userIDStr.(string))
 		if err != nil {
@@ -138,61 +198,47 @@ func ApproveVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Find the vacation request and verify manager authority
-		var vacationRequest models.VacationRequest
-		if err := db.Preload("User").
-			Joins("JOIN users ON users.id = vacation_requests.user_id").
-			Where("vacation_requests.id = ? AND users.manager_id = ? AND vacation_requests.status = ?",
-				requestID, managerID, models.StatusPending).
-			First(&vacationRequest).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
+		// Record the decision on the current step of the approval chain
+		result, err := services.DecideVacationRequest(db, services.DecisionInput{
+			RequestID: requestID,
+			ActorID:   managerID,
+			ActorRole: models.UserRole(userRoleStr),
+			Approve:   true,
+			Comment:   req.Comment,
+			Version:   req.Version,
+		})
+		if err != nil {
+			switch err {
+			case services.ErrRequestNotFound, services.ErrNotApprover:
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": "Vacation request not found or you don't have permission to approve it",
 				})
 				return
+			case services.ErrRequestNotPending:
+				c.JSON(http.StatusConflict, gin.H{
+					"error": "Vacation request is no longer pending",
+				})
+				return
+			case services.ErrRequestChanged:
+				c.JSON(http.StatusConflict, gin.H{
+					"error": "Vacation request was changed by someone else, reload it and try again",
+				})
+				return
+			case services.ErrInsufficientBalance:
+				c.JSON(http.StatusConflict, gin.H{
+					"error": "The employee no longer has enough vacation balance",
+				})
+				return
 			}
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to fetch vacation request",
-			})
-			return
-		}
-
-		// Update the request
-		now := time.Now()
-		vacationRequest.Status = models.StatusApproved
-		vacationRequest.ApprovedBy = &managerID
-		vacationRequest.ApprovalDate = &now
-		vacationRequest.ApprovalComment = req.Comment
-
-		if err := db.Save(&vacationRequest).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to approve vacation request",
 			})
 			return
 		}
-
-		// Update user's vacation balance
-		var user models.User
-		if err := db.Where("id = ?", vacationRequest.UserID).First(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to fetch user information",
-			})
-			return
-		}
-
-		user.VacationBalance -= vacationRequest.BusinessDays
-		if err := db.Save(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to update user vacation balance",
-			})
-			return
-		}
-
-		// TODO: Create notification for employee
-		// TODO: Send email notification
+		vacationRequest := *result.Request
 
 		// Load updated data for response
-		if err := db.Preload("User").Preload("Approver").First(&vacationRequest, vacationRequest.ID).Error; err != nil {
+		if err := db.Preload("User").Preload("Approver").Preload("Substitute").Preload("ApprovalSteps", orderedSteps).First(&vacationRequest, vacationRequest.ID).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to load vacation request details",
 			})
@@ -213,13 +259,8 @@ func RejectVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		userRole, exists := c.Get(middleware.UserRoleKey)
-		if !exists || (userRole != "manager" && userRole != "admin") {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-			})
-			return
-		}
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
 
 		managerID, err := uuid.Parse(userIDStr.(string))
 		if err != nil {
@@ -253,44 +294,216 @@ func RejectVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Find the vacation request and verify manager authority
-		var vacationRequest models.VacationRequest
-		if err := db.Preload("User").
-			Joins("JOIN users ON users.id = vacation_requests.user_id").
-			Where("vacation_requests.id = ? AND users.manager_id = ? AND vacation_requests.status = ?",
-				requestID, managerID, models.StatusPending).
-			First(&vacationRequest).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
+		// Record the decision on the current step of the approval chain
+		result, err := services.DecideVacationRequest(db, services.DecisionInput{
+			RequestID: requestID,
+			ActorID:   managerID,
+			ActorRole: models.UserRole(userRoleStr),
+			Approve:   false,
+			Comment:   req.Comment,
+			Version:   req.Version,
+		})
+		if err != nil {
+			switch err {
+			case services.ErrRequestNotFound, services.ErrNotApprover:
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": "Vacation request not found or you don't have permission to reject it",
 				})
 				return
+			case services.ErrRequestNotPending:
+				c.JSON(http.StatusConflict, gin.H{
+					"error": "Vacation request is no longer pending",
+				})
+				return
+			case services.ErrRequestChanged:
+				c.JSON(http.StatusConflict, gin.H{
+					"error": "Vacation request was changed by someone else, reload it and try again",
+				})
+				return
 			}
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to fetch vacation request",
+				"error": "Failed to reject vacation request",
 			})
 			return
 		}
+		vacationRequest := *result.Request
 
-		// Update the request
-		now := time.Now()
-		vacationRequest.Status = models.StatusRejected
-		vacationRequest.ApprovedBy = &managerID
-		vacationRequest.ApprovalDate = &now
-		vacationRequest.ApprovalComment = req.Comment
-
-		if err := db.Save(&vacationRequest).Error; err != nil {
+		// Load updated data for response
+		if err := db.Preload("User").Preload("Approver").Preload("Substitute").Preload("ApprovalSteps", orderedSteps).First(&vacationRequest, vacationRequest.ID).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to reject vacation request",
+				"error": "Failed to load vacation request details",
 			})
 			return
 		}
 
-		// TODO: Create notification for employee
-		// TODO: Send email notification
+		c.JSON(http.StatusOK, vacationRequest.ToResponse())
+	}
+}
+
+// Per-item outcomes of a bulk decision
+const (
+	bulkResultSuccess  = "success"
+	bulkResultNotFound = "not_found"
+	bulkResultConflict = "conflict"
+	bulkResultInvalid  = "invalid"
+	bulkResultError    = "error"
+)
+
+func BulkDecideVacationRequests(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
+
+		managerID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var req models.BulkDecisionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		approve := req.Decision == "approve"
+		response := models.BulkDecisionResponse{Results: []*models.BulkDecisionResult{}}
+		seen := map[uuid.UUID]bool{}
+
+		// Each item is decided in its own transaction so one failure does not undo the others
+		for _, item := range req.Items {
+			result := &models.BulkDecisionResult{ID: item.ID.String()}
+			response.Results = append(response.Results, result)
+
+			comment := item.Comment
+			if comment == "" {
+				comment = req.Comment
+			}
+
+			switch {
+			case seen[item.ID]:
+				result.Status = bulkResultInvalid
+				result.Error = "Duplicate request ID"
+			case !approve && comment == "":
+				result.Status = bulkResultInvalid
+				result.Error = "Comment is required for rejection"
+			default:
+				decision, err := services.DecideVacationRequest(db, services.DecisionInput{
+					RequestID: item.ID,
+					ActorID:   managerID,
+					ActorRole: models.UserRole(userRoleStr),
+					Approve:   approve,
+					Comment:   comment,
+					Version:   item.Version,
+				})
+				switch err {
+				case nil:
+					result.Status = bulkResultSuccess
+					result.Request = decision.Request.ToResponse()
+				case services.ErrRequestNotFound, services.ErrNotApprover:
+					result.Status = bulkResultNotFound
+					result.Error = "Vacation request not found or you don't have permission to decide it"
+				case services.ErrRequestNotPending:
+					result.Status = bulkResultConflict
+					result.Error = "Vacation request is no longer pending"
+				case services.ErrRequestChanged:
+					result.Status = bulkResultConflict
+					result.Error = "Vacation request was changed by someone else"
+				case services.ErrInsufficientBalance:
+					result.Status = bulkResultConflict
+					result.Error = "The employee no longer has enough vacation balance"
+				default:
+					result.Status = bulkResultError
+					result.Error = "Failed to process vacation request"
+				}
+			}
+			seen[item.ID] = true
+
+			if result.Status == bulkResultSuccess {
+				response.Succeeded++
+			} else {
+				response.Failed++
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func RevokeAutoApproval(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
+
+		managerID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		requestID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		var req models.RevokeAutoApprovalRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Comment is required for revocation",
+			})
+			return
+		}
+
+		vacationRequest, err := services.RevokeAutoApproval(db, requestID, managerID, models.UserRole(userRoleStr), req.Comment)
+		if err != nil {
+			switch err {
+			case services.ErrRequestNotFound, services.ErrNotRequesterManager:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Vacation request not found or you don't have permission to revoke it",
+				})
+			case services.ErrNotAutoApproved:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Only auto-approved requests can be revoked",
+				})
+			case services.ErrRevokeWindowClosed:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "The revoke window for this request has closed",
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to revoke auto-approval",
+				})
+			}
+			return
+		}
 
 		// Load updated data for response
-		if err := db.Preload("User").Preload("Approver").First(&vacationRequest, vacationRequest.ID).Error; err != nil {
+		if err := db.Preload("User").Preload("Approver").Preload("Substitute").Preload("ApprovalSteps", orderedSteps).First(vacationRequest, vacationRequest.ID).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to load vacation request details",
 			})
@@ -347,12 +560,21 @@ func GetTeamCalendar(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		subtree, message := parseTeamScope(c)
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
 		// Get all approved vacation requests for team members in the date range
 		var requests []models.VacationRequest
 		if err := db.Preload("User").
 			Joins("JOIN users ON users.id = vacation_requests.user_id").
-			Where("users.manager_id = ? AND vacation_requests.status = ? AND vacation_requests.start_date <= ? AND vacation_requests.end_date >= ?",
-				managerID, models.StatusApproved, endDate, startDate).
+			Scopes(teamMembersOf(managerID, subtree)).
+			Where("vacation_requests.status IN ? AND vacation_requests.start_date <= ? AND vacation_requests.end_date >= ?",
+				models.GrantedStatuses, endDate, startDate).
 			Order("vacation_requests.start_date ASC").
 			Find(&requests).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -368,6 +590,8 @@ func GetTeamCalendar(db *gorm.DB) gin.HandlerFunc {
 				"id":            req.ID.String(),
 				"user_id":       req.UserID.String(),
 				"user_name":     req.User.Name,
+				"manager_id":    req.User.ManagerID,
+				"status":        string(req.Status),
 				"start_date":    req.StartDate.Format("2006-01-02"),
 				"end_date":      req.EndDate.Format("2006-01-02"),
 				"business_days": req.BusinessDays,
@@ -412,10 +636,19 @@ func GetTeamStats(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		subtree, message := parseTeamScope(c)
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
 		// Get team members count
 		var teamMembersCount int64
 		if err := db.Model(&models.User{}).
-			Where("manager_id = ? AND active = ?", managerID, true).
+			Scopes(teamMembersOf(managerID, subtree)).
+			Where("users.active = ?", true).
 			Count(&teamMembersCount).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to count team members",
@@ -427,7 +660,8 @@ func GetTeamStats(db *gorm.DB) gin.HandlerFunc {
 		var pendingRequestsCount int64
 		if err := db.Model(&models.VacationRequest{}).
 			Joins("JOIN users ON users.id = vacation_requests.user_id").
-			Where("users.manager_id = ? AND vacation_requests.status = ?", managerID, models.StatusPending).
+			Scopes(teamMembersOf(managerID, subtree)).
+			Where("vacation_requests.status = ?", models.StatusPending).
 			Count(&pendingRequestsCount).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to count pending requests",
@@ -443,8 +677,9 @@ func GetTeamStats(db *gorm.DB) gin.HandlerFunc {
 		var approvedRequestsCount int64
 		if err := db.Model(&models.VacationRequest{}).
 			Joins("JOIN users ON users.id = vacation_requests.user_id").
-			Where("users.manager_id = ? AND vacation_requests.status = ? AND vacation_requests.start_date >= ? AND vacation_requests.start_date <= ?",
-				managerID, models.StatusApproved, startOfYear, endOfYear).
+			Scopes(teamMembersOf(managerID, subtree)).
+			Where("vacation_requests.status IN ? AND vacation_requests.start_date >= ? AND vacation_requests.start_date <= ?",
+				models.GrantedStatuses, startOfYear, endOfYear).
 			Count(&approvedRequestsCount).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to count approved requests",
@@ -457,8 +692,9 @@ func GetTeamStats(db *gorm.DB) gin.HandlerFunc {
 		if err := db.Model(&models.VacationRequest{}).
 			Select("COALESCE(SUM(business_days), 0)").
 			Joins("JOIN users ON users.id = vacation_requests.user_id").
-			Where("users.manager_id = ? AND vacation_requests.status = ? AND vacation_requests.start_date >= ? AND vacation_requests.start_date <= ?",
-				managerID, models.StatusApproved, startOfYear, endOfYear).
+			Scopes(teamMembersOf(managerID, subtree)).
+			Where("vacation_requests.status IN ? AND vacation_requests.start_date >= ? AND vacation_requests.start_date <= ?",
+				models.GrantedStatuses, startOfYear, endOfYear).
 			Scan(&totalVacationDays).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to calculate vacation days",
@@ -468,7 +704,7 @@ func GetTeamStats(db *gorm.DB) gin.HandlerFunc {
 
 		// Get team members with their vacation balances
 		var teamMembers []models.User
-		if err := db.Where("manager_id = ? AND active = ?", managerID, true).
+		if err := db.Scopes(teamMembersOf(managerID, subtree)).Where("users.active = ?", true).
 			Order("name ASC").Find(&teamMembers).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to fetch team members",
@@ -484,6 +720,7 @@ func GetTeamStats(db *gorm.DB) gin.HandlerFunc {
 				"email":            member.Email,
 				"vacation_balance": member.VacationBalance,
 				"department":       member.Department,
+				"manager_id":       member.ManagerID,
 			})
 		}
 
@@ -493,6 +730,7 @@ func GetTeamStats(db *gorm.DB) gin.HandlerFunc {
 			"approved_requests_count": approvedRequestsCount,
 			"total_vacation_days":     totalVacationDays,
 			"current_year":            currentYear,
+			"scope":                   c.DefaultQuery("scope", "direct"),
 			"team_members":            teamMembersData,
 		}
 
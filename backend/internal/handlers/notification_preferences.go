@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// validateNotificationPreferences checks the values that binding tags cannot
+func validateNotificationPreferences(req *models.UpdateNotificationPreferencesRequest) string {
+	if req.Locale != nil && services.EmailLocale(*req.Locale) != *req.Locale {
+		return "Unsupported locale"
+	}
+	if req.TimeZone != nil {
+		if _, err := time.LoadLocation(*req.TimeZone); err != nil || *req.TimeZone == "" || *req.TimeZone == "Local" {
+			return "Invalid time zone"
+		}
+	}
+	for _, value := range []*string{req.QuietHoursStart, req.QuietHoursEnd} {
+		if value == nil || *value == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", *value); err != nil {
+			return "Quiet hours must use the HH:MM format"
+		}
+	}
+
+	knownTypes := map[models.NotificationType]bool{"": true}
+	for _, notificationType := range models.NotificationTypes {
+		knownTypes[notificationType] = true
+	}
+	seen := map[models.ChannelPreferenceInput]bool{}
+	for _, input := range req.Channels {
+		key := models.ChannelPreferenceInput{Type: input.Type, Channel: input.Channel}
+		if seen[key] {
+			return "Each type and channel may only be listed once"
+		}
+		seen[key] = true
+
+		if !knownTypes[input.Type] {
+			return "Unknown notification type " + string(input.Type)
+		}
+		switch input.Channel {
+		case models.ChannelEmail, models.ChannelWebhook:
+		case models.ChannelInApp:
+			return "The in-app channel cannot be turned off"
+		default:
+			return "Unknown channel " + input.Channel
+		}
+	}
+	return ""
+}
+
+// loadNotificationPreferences builds the response of the preference endpoints
+func loadNotificationPreferences(db *gorm.DB, user *models.User) (*models.NotificationPreferencesResponse, error) {
+	settings, err := services.LoadNotificationSettings(db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var preferences []models.NotificationChannelPreference
+	if err := db.Where("user_id = ?", user.ID).Order("type ASC, channel ASC").Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+
+	return settings.ToResponse(user.Locale, preferences), nil
+}
+
+func GetNotificationPreferences(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var user models.User
+		if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch user",
+			})
+			return
+		}
+
+		response, err := loadNotificationPreferences(db, &user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch notification preferences",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// UpdateNotificationPreferences changes only the fields sent; channels, when
+// sent, replace every channel preference of the user
+func UpdateNotificationPreferences(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var req models.UpdateNotificationPreferencesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+		if message := validateNotificationPreferences(&req); message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		var user models.User
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("id = ?", userID).First(&user).Error; err != nil {
+				return err
+			}
+			if req.Locale != nil {
+				user.Locale = *req.Locale
+				if err := tx.Model(&user).Update("locale", user.Locale).Error; err != nil {
+					return err
+				}
+				if err := services.PublishUserUpdated(tx, userID); err != nil {
+					return err
+				}
+			}
+
+			settings, err := services.LoadNotificationSettings(tx, userID)
+			if err != nil {
+				return err
+			}
+			if req.TimeZone != nil {
+				settings.TimeZone = *req.TimeZone
+			}
+			if req.QuietHoursStart != nil {
+				settings.QuietHoursStart = *req.QuietHoursStart
+			}
+			if req.QuietHoursEnd != nil {
+				settings.QuietHoursEnd = *req.QuietHoursEnd
+			}
+			if req.Digest != nil {
+				settings.Digest = *req.Digest
+			}
+			if req.DigestHour != nil {
+				settings.DigestHour = *req.DigestHour
+			}
+			if req.DigestWeekday != nil {
+				settings.DigestWeekday = time.Weekday(*req.DigestWeekday)
+			}
+			if err := tx.Save(settings).Error; err != nil {
+				return err
+			}
+
+			if req.Channels == nil {
+				return nil
+			}
+			if err := tx.Where("user_id = ?", userID).Delete(&models.NotificationChannelPreference{}).Error; err != nil {
+				return err
+			}
+			for _, input := range req.Channels {
+				preference := models.NotificationChannelPreference{
+					UserID:  userID,
+					Type:    input.Type,
+					Channel: input.Channel,
+					Enabled: *input.Enabled,
+				}
+				if err := tx.Create(&preference).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update notification preferences",
+			})
+			return
+		}
+
+		response, err := loadNotificationPreferences(db, &user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch notification preferences",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -2,31 +2,260 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// notificationFilters narrows the signed-in user's notifications by the type
+// (comma-separated) and read query parameters
+func notificationFilters(c *gin.Context) (func(*gorm.DB) *gorm.DB, string) {
+	var types []string
+	if value := c.Query("type"); value != "" {
+		types = strings.Split(value, ",")
+	}
+
+	var read *bool
+	if value := c.Query("read"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, "Invalid read filter, use true or false"
+		}
+		read = &parsed
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if len(types) > 0 {
+			db = db.Where("type IN ?", types)
+		}
+		if read != nil {
+			db = db.Where("read = ?", *read)
+		}
+		return db
+	}, ""
+}
+
 func GetNotifications(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "Get notifications - TODO",
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		filters, message := notificationFilters(c)
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		// Parse query parameters
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+
+		if page < 1 {
+			page = 1
+		}
+		if perPage < 1 || perPage > 100 {
+			perPage = 20
+		}
+
+		offset := (page - 1) * perPage
+
+		query := db.Model(&models.Notification{}).Where("user_id = ?", userID).Scopes(filters)
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to count notifications",
+			})
+			return
+		}
+
+		var unread int64
+		if err := db.Model(&models.Notification{}).Where("user_id = ? AND read = ?", userID, false).Count(&unread).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to count unread notifications",
+			})
+			return
+		}
+
+		var notifications []models.Notification
+		if err := query.Preload("Deliveries").Order("created_at DESC").Offset(offset).Limit(perPage).Find(&notifications).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch notifications",
+			})
+			return
+		}
+
+		responseNotifications := make([]*models.NotificationResponse, 0, len(notifications))
+		for i := range notifications {
+			responseNotifications = append(responseNotifications, notifications[i].ToResponse())
+		}
+
+		totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+
+		c.JSON(http.StatusOK, models.NotificationsListResponse{
+			Notifications: responseNotifications,
+			Total:         total,
+			UnreadCount:   unread,
+			Page:          page,
+			PerPage:       perPage,
+			TotalPages:    totalPages,
 		})
 	}
 }
 
-func MarkNotificationAsRead(db *gorm.DB) gin.HandlerFunc {
+func GetUnreadNotificationCount(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var unread int64
+		if err := db.Model(&models.Notification{}).Where("user_id = ? AND read = ?", userID, false).Count(&unread).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to count unread notifications",
+			})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"message": "Mark notification as read - TODO",
+			"unread_count": unread,
 		})
 	}
 }
 
+func MarkNotificationAsRead(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		notificationID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid notification ID format",
+			})
+			return
+		}
+
+		// Other users' notifications are reported as missing
+		var notification models.Notification
+		if err := db.Preload("Deliveries").Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Notification not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch notification",
+			})
+			return
+		}
+
+		// Marking again keeps the time it was first read
+		if !notification.Read {
+			now := time.Now()
+			notification.Read = true
+			notification.ReadAt = &now
+			if err := db.Model(&notification).Select("read", "read_at").Updates(&notification).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to mark notification as read",
+				})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, notification.ToResponse())
+	}
+}
+
 func MarkAllNotificationsAsRead(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		filters, message := notificationFilters(c)
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		result := db.Model(&models.Notification{}).
+			Where("user_id = ? AND read = ?", userID, false).
+			Scopes(filters).
+			Updates(map[string]interface{}{
+				"read":    true,
+				"read_at": time.Now(),
+			})
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to mark notifications as read",
+			})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"message": "Mark all notifications as read - TODO",
+			"updated": result.RowsAffected,
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// buildOrgChartNode assembles the subtree below the user and fills in the
+// counters. Users already placed are skipped so a reporting cycle cannot loop.
+func buildOrgChartNode(user *models.User, reports map[uuid.UUID][]*models.User, returnDates map[uuid.UUID]time.Time, placed map[uuid.UUID]bool) *models.OrgChartNode {
+	placed[user.ID] = true
+
+	node := &models.OrgChartNode{
+		ID:         user.ID.String(),
+		Name:       user.Name,
+		Email:      user.Email,
+		Role:       string(user.Role),
+		Department: user.Department,
+		Reports:    []*models.OrgChartNode{},
+	}
+	if user.ManagerID != nil {
+		managerIDStr := user.ManagerID.String()
+		node.ManagerID = &managerIDStr
+	}
+	if returnDate, ok := returnDates[user.ID]; ok {
+		node.OnLeave = true
+		returnDateStr := returnDate.Format("2006-01-02")
+		node.ReturnDate = &returnDateStr
+	}
+
+	for _, report := range reports[user.ID] {
+		if placed[report.ID] {
+			continue
+		}
+		child := buildOrgChartNode(report, reports, returnDates, placed)
+		node.Reports = append(node.Reports, child)
+		node.DirectReports++
+		node.Headcount += child.Headcount + 1
+		node.OnLeaveCount += child.OnLeaveCount
+		if child.OnLeave {
+			node.OnLeaveCount++
+		}
+	}
+
+	return node
+}
+
+func GetOrgChart(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := db.Where("active = ?", true)
+
+		// An optional root limits the chart to that person and their subtree
+		var rootID *uuid.UUID
+		if rootIDStr := c.Query("root"); rootIDStr != "" {
+			id, err := uuid.Parse(rootIDStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid root format",
+				})
+				return
+			}
+			rootID = &id
+			query = query.Where("id = ? OR id IN (?)", id, services.SubtreeQuery(db, id))
+		}
+
+		var users []models.User
+		if err := query.Order("name ASC").Find(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch users",
+			})
+			return
+		}
+
+		if rootID != nil && len(users) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		// People out today, with the day they are back
+		today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+		var leaves []models.VacationRequest
+		if err := db.Where("status IN ? AND start_date <= ? AND end_date >= ?",
+			models.OnLeaveStatuses, time.Now(), today).
+			Find(&leaves).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch current leaves",
+			})
+			return
+		}
+
+		returnDates := map[uuid.UUID]time.Time{}
+		for _, leave := range leaves {
+			returnDate := leave.EndDate.AddDate(0, 0, 1)
+			if current, ok := returnDates[leave.UserID]; !ok || returnDate.After(current) {
+				returnDates[leave.UserID] = returnDate
+			}
+		}
+
+		byID := map[uuid.UUID]*models.User{}
+		for i := range users {
+			byID[users[i].ID] = &users[i]
+		}
+
+		reports := map[uuid.UUID][]*models.User{}
+		var roots []*models.User
+		for i := range users {
+			user := &users[i]
+			isRoot := user.ManagerID == nil || byID[*user.ManagerID] == nil
+			if rootID != nil {
+				isRoot = user.ID == *rootID
+			}
+			if isRoot {
+				roots = append(roots, user)
+			} else if user.ManagerID != nil {
+				reports[*user.ManagerID] = append(reports[*user.ManagerID], user)
+			}
+		}
+
+		placed := map[uuid.UUID]bool{}
+		nodes := []*models.OrgChartNode{}
+		headcount, onLeave := 0, 0
+		for _, root := range roots {
+			node := buildOrgChartNode(root, reports, returnDates, placed)
+			nodes = append(nodes, node)
+			headcount += node.Headcount + 1
+			onLeave += node.OnLeaveCount
+			if node.OnLeave {
+				onLeave++
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"as_of":     today.Format("2006-01-02"),
+			"headcount": headcount,
+			"on_leave":  onLeave,
+			"roots":     nodes,
+		})
+	}
+}
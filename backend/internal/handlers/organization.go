@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// orgFilters holds the organization-wide filters shared by the admin/HR views
+type orgFilters struct {
+	Department string
+	ManagerID  *uuid.UUID
+	NoManager  bool
+	Status     string
+	StartDate  *time.Time
+	EndDate    *time.Time
+}
+
+// parseOrgFilters reads department, manager_id ("none" for employees without
+// a manager), status (comma-separated) and start_date/end_date from the query string
+func parseOrgFilters(c *gin.Context, defaultStatus string) (*orgFilters, string) {
+	filters := &orgFilters{
+		Department: c.Query("department"),
+		Status:     c.DefaultQuery("status", defaultStatus),
+	}
+
+	if managerIDStr := c.Query("manager_id"); managerIDStr != "" {
+		if managerIDStr == "none" {
+			filters.NoManager = true
+		} else {
+			managerID, err := uuid.Parse(managerIDStr)
+			if err != nil {
+				return nil, "Invalid manager_id format"
+			}
+			filters.ManagerID = &managerID
+		}
+	}
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			return nil, "Invalid start_date format (YYYY-MM-DD)"
+		}
+		filters.StartDate = &startDate
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			return nil, "Invalid end_date format (YYYY-MM-DD)"
+		}
+		filters.EndDate = &endDate
+	}
+
+	return filters, ""
+}
+
+// usersScope applies the people filters to a query joined with users
+func (f *orgFilters) usersScope(db *gorm.DB) *gorm.DB {
+	if f.Department != "" {
+		db = db.Where("users.department = ?", f.Department)
+	}
+	if f.ManagerID != nil {
+		db = db.Where("users.manager_id = ?", *f.ManagerID)
+	}
+	if f.NoManager {
+		db = db.Where("users.manager_id IS NULL")
+	}
+	return db
+}
+
+// requestsScope applies every filter to a vacation request query
+func (f *orgFilters) requestsScope(db *gorm.DB) *gorm.DB {
+	db = db.Joins("JOIN users ON users.id = vacation_requests.user_id").Scopes(f.usersScope)
+	if f.Status != "" && f.Status != "all" {
+		db = db.Where("vacation_requests.status IN ?", strings.Split(f.Status, ","))
+	}
+	// Requests overlapping the period
+	if f.StartDate != nil {
+		db = db.Where("vacation_requests.end_date >= ?", *f.StartDate)
+	}
+	if f.EndDate != nil {
+		db = db.Where("vacation_requests.start_date <= ?", *f.EndDate)
+	}
+	return db
+}
+
+func GetOrgVacationRequests(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filters, message := parseOrgFilters(c, string(models.StatusPending))
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		// Parse query parameters
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+		if page < 1 {
+			page = 1
+		}
+		if perPage < 1 || perPage > 100 {
+			perPage = 10
+		}
+
+		offset := (page - 1) * perPage
+
+		query := db.Preload("User").Preload("User.Manager").Preload("Approver").Preload("Substitute").
+			Preload("ApprovalSteps", orderedSteps).
+			Preload("History", chronologicalHistory).Preload("History.Actor").
+			Scopes(filters.requestsScope)
+
+		// Count total
+		var total int64
+		if err := query.Model(&models.VacationRequest{}).Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to count vacation requests",
+			})
+			return
+		}
+
+		// Get requests, oldest first so the queue is worked in order
+		var requests []models.VacationRequest
+		if err := query.Order("vacation_requests.created_at ASC").
+			Offset(offset).Limit(perPage).Find(&requests).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vacation requests",
+			})
+			return
+		}
+
+		// Convert to response format
+		var responseRequests []*models.VacationRequestResponse
+		for _, req := range requests {
+			responseRequests = append(responseRequests, req.ToResponse())
+		}
+
+		totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+
+		response := models.VacationRequestsListResponse{
+			Requests:   responseRequests,
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetOrgCalendar(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted := make([]string, len(models.GrantedStatuses))
+		for i, status := range models.GrantedStatuses {
+			granted[i] = string(status)
+		}
+		filters, message := parseOrgFilters(c, strings.Join(granted, ","))
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		// Default to the next three months, like the team calendar
+		if filters.StartDate == nil {
+			startDate, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+			filters.StartDate = &startDate
+		}
+		if filters.EndDate == nil {
+			endDate := filters.StartDate.AddDate(0, 3, 0)
+			filters.EndDate = &endDate
+		}
+
+		var requests []models.VacationRequest
+		if err := db.Preload("User").
+			Scopes(filters.requestsScope).
+			Order("vacation_requests.start_date ASC").
+			Find(&requests).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch organization calendar",
+			})
+			return
+		}
+
+		// Convert to response format
+		var calendarEntries []map[string]interface{}
+		for _, req := range requests {
+			calendarEntries = append(calendarEntries, map[string]interface{}{
+				"id":            req.ID.String(),
+				"user_id":       req.UserID.String(),
+				"user_name":     req.User.Name,
+				"department":    req.User.Department,
+				"status":        string(req.Status),
+				"start_date":    req.StartDate.Format("2006-01-02"),
+				"end_date":      req.EndDate.Format("2006-01-02"),
+				"business_days": req.BusinessDays,
+			})
+		}
+
+		response := gin.H{
+			"start_date": filters.StartDate.Format("2006-01-02"),
+			"end_date":   filters.EndDate.Format("2006-01-02"),
+			"entries":    calendarEntries,
+			"total":      len(calendarEntries),
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetOrgStats(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filters, message := parseOrgFilters(c, "all")
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		// Default to the current year
+		currentYear := time.Now().Year()
+		if filters.StartDate == nil {
+			startOfYear := time.Date(currentYear, 1, 1, 0, 0, 0, 0, time.UTC)
+			filters.StartDate = &startOfYear
+		}
+		if filters.EndDate == nil {
+			endOfYear := time.Date(currentYear, 12, 31, 23, 59, 59, 0, time.UTC)
+			filters.EndDate = &endOfYear
+		}
+
+		// Headcount of the filtered population
+		var headcount int64
+		if err := db.Model(&models.User{}).
+			Scopes(filters.usersScope).
+			Where("users.active = ?", true).
+			Count(&headcount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to count employees",
+			})
+			return
+		}
+
+		var withoutManager int64
+		if err := db.Model(&models.User{}).
+			Scopes(filters.usersScope).
+			Where("users.active = ? AND users.manager_id IS NULL", true).
+			Count(&withoutManager).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to count employees without manager",
+			})
+			return
+		}
+
+		// Requests and days by status
+		var byStatus []struct {
+			Status   string `json:"status"`
+			Requests int64  `json:"requests"`
+			Days     int64  `json:"days"`
+		}
+		if err := db.Model(&models.VacationRequest{}).
+			Select("vacation_requests.status AS status, COUNT(*) AS requests, COALESCE(SUM(vacation_requests.business_days), 0) AS days").
+			Scopes(filters.requestsScope).
+			Group("vacation_requests.status").
+			Scan(&byStatus).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to calculate statistics by status",
+			})
+			return
+		}
+
+		// Requests and days by department
+		var byDepartment []struct {
+			Department string `json:"department"`
+			Requests   int64  `json:"requests"`
+			Days       int64  `json:"days"`
+		}
+		if err := db.Model(&models.VacationRequest{}).
+			Select("users.department AS department, COUNT(*) AS requests, COALESCE(SUM(vacation_requests.business_days), 0) AS days").
+			Scopes(filters.requestsScope).
+			Group("users.department").
+			Order("users.department ASC").
+			Scan(&byDepartment).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to calculate statistics by department",
+			})
+			return
+		}
+
+		// Balances of the filtered population
+		var balances struct {
+			Total   int64   `json:"total"`
+			Average float64 `json:"average"`
+		}
+		if err := db.Model(&models.User{}).
+			Select("COALESCE(SUM(users.vacation_balance), 0) AS total, COALESCE(AVG(users.vacation_balance), 0) AS average").
+			Scopes(filters.usersScope).
+			Where("users.active = ?", true).
+			Scan(&balances).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to calculate vacation balances",
+			})
+			return
+		}
+
+		response := gin.H{
+			"start_date":                filters.StartDate.Format("2006-01-02"),
+			"end_date":                  filters.EndDate.Format("2006-01-02"),
+			"headcount":                 headcount,
+			"employees_without_manager": withoutManager,
+			"by_status":                 byStatus,
+			"by_department":             byDepartment,
+			"total_vacation_balance":    balances.Total,
+			"average_vacation_balance":  balances.Average,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetOutboxMetrics reports the outbox backlog and the lag of each consumer
+func GetOutboxMetrics(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metrics, err := services.OutboxMetrics(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch outbox metrics",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, metrics)
+	}
+}
@@ -0,0 +1,471 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// slackDateLayouts are the date formats accepted by /ferias pedir
+var slackDateLayouts = []string{"2006-01-02", "02/01/2006"}
+
+// slackTeamHorizonDays is how far ahead /ferias equipe lists absences
+const slackTeamHorizonDays = 30
+
+// slackMaxPendingShown keeps the /ferias equipe reply within Slack's block limit
+const slackMaxPendingShown = 10
+
+// slackRejectCallbackID identifies the modal asking for a rejection reason
+const slackRejectCallbackID = "reject"
+
+// slackRejectMetadata is carried by the rejection modal from the button
+// click to its submission
+type slackRejectMetadata struct {
+	// Value is the value of the clicked button, "<request ID>:<step ID>"
+	Value       string `json:"value"`
+	ResponseURL string `json:"response_url"`
+}
+
+const slackUsage = "Comandos disponíveis:\n" +
+	"• `/ferias saldo` - seu saldo e suas próximas férias\n" +
+	"• `/ferias pedir AAAA-MM-DD AAAA-MM-DD [motivo]` - solicitar férias\n" +
+	"• `/ferias equipe` - ausências da equipe e solicitações aguardando sua decisão"
+
+var slackStatusLabels = map[models.VacationStatus]string{
+	models.StatusPending:    "aguardando aprovação",
+	models.StatusApproved:   "aprovada",
+	models.StatusInProgress: "em andamento",
+}
+
+// slackText is a reply only the caller sees
+func slackText(text string) *models.SlackMessage {
+	return &models.SlackMessage{ResponseType: "ephemeral", Text: text}
+}
+
+func slackSection(text string) models.SlackBlock {
+	return models.SlackBlock{Type: "section", Text: &models.SlackText{Type: "mrkdwn", Text: text}}
+}
+
+func slackPeriod(request *models.VacationRequest) string {
+	return fmt.Sprintf("%s a %s", request.StartDate.Format("02/01/2006"), request.EndDate.Format("02/01/2006"))
+}
+
+// readSlackRequest verifies the request signature and returns its form
+// fields, responding with the error when it cannot
+func readSlackRequest(c *gin.Context) (url.Values, bool) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return nil, false
+	}
+
+	if err := services.VerifySlackRequest(c.GetHeader("X-Slack-Request-Timestamp"), c.GetHeader("X-Slack-Signature"), body); err != nil {
+		if err == services.ErrSlackDisabled {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Slack integration is not enabled",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid request signature",
+		})
+		return nil, false
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return nil, false
+	}
+	return values, true
+}
+
+// slackUser resolves the caller. A non-empty message tells them why they
+// cannot use the integration.
+func slackUser(db *gorm.DB, teamID, slackUserID string) (*models.User, string) {
+	user, err := services.ResolveSlackUser(db, teamID, slackUserID)
+	if err == services.ErrSlackUserNotLinked {
+		return nil, "Não encontramos um usuário ativo do sistema de férias com o e-mail da sua conta do Slack."
+	}
+	if err != nil {
+		log.Printf("Failed to resolve slack user %s: %v", slackUserID, err)
+		return nil, "Não foi possível identificar seu usuário. Tente novamente mais tarde."
+	}
+	return user, ""
+}
+
+// SlackCommand answers the /ferias slash command
+func SlackCommand(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		values, ok := readSlackRequest(c)
+		if !ok {
+			return
+		}
+
+		user, message := slackUser(db, values.Get("team_id"), values.Get("user_id"))
+		if message != "" {
+			c.JSON(http.StatusOK, slackText(message))
+			return
+		}
+
+		args := strings.Fields(values.Get("text"))
+		subcommand := ""
+		if len(args) > 0 {
+			subcommand = strings.ToLower(args[0])
+		}
+
+		var reply *models.SlackMessage
+		switch subcommand {
+		case "saldo":
+			reply = slackBalance(db, user)
+		case "pedir":
+			reply = slackRequestLeave(db, user, args[1:])
+		case "equipe":
+			reply = slackTeam(db, user)
+		default:
+			reply = slackText(slackUsage)
+		}
+
+		c.JSON(http.StatusOK, reply)
+	}
+}
+
+// slackBalance shows the caller's balance and the leave they have reserved
+func slackBalance(db *gorm.DB, user *models.User) *models.SlackMessage {
+	var requests []models.VacationRequest
+	if err := db.Where("user_id = ? AND status IN ? AND end_date >= ?",
+		user.ID, models.ReservedStatuses, time.Now().Truncate(24*time.Hour)).
+		Order("start_date ASC").Find(&requests).Error; err != nil {
+		log.Printf("Failed to fetch vacation requests of user %s: %v", user.ID, err)
+		return slackText("Não foi possível consultar seu saldo. Tente novamente mais tarde.")
+	}
+
+	text := fmt.Sprintf("Seu saldo de férias é de *%d* dia(s) útil(eis).", user.VacationBalance)
+	if len(requests) > 0 {
+		text += "\n\n*Próximas férias*"
+		for i := range requests {
+			text += fmt.Sprintf("\n• %s (%d dias úteis) - %s",
+				slackPeriod(&requests[i]), requests[i].BusinessDays, slackStatusLabels[requests[i].Status])
+		}
+	}
+	return slackText(text)
+}
+
+// slackRequestLeave submits a vacation request with the same checks as the app
+func slackRequestLeave(db *gorm.DB, user *models.User, args []string) *models.SlackMessage {
+	if len(args) < 2 {
+		return slackText("Use `/ferias pedir AAAA-MM-DD AAAA-MM-DD [motivo]`, com as datas de início e fim.")
+	}
+
+	var dates [2]time.Time
+	for i := range dates {
+		parsed := false
+		for _, layout := range slackDateLayouts {
+			if date, err := time.Parse(layout, args[i]); err == nil {
+				dates[i] = date
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			return slackText(fmt.Sprintf("Data inválida: %s. Use o formato AAAA-MM-DD.", args[i]))
+		}
+	}
+	startDate, endDate := dates[0], dates[1]
+	if endDate.Before(startDate) {
+		return slackText("A data de fim deve ser posterior à data de início.")
+	}
+
+	message, err := checkSubmission(db, user, models.LeaveTypeVacation, startDate, endDate)
+	if err != nil {
+		log.Printf("Failed to validate vacation request of user %s: %v", user.ID, err)
+		return slackText("Não foi possível solicitar as férias. Tente novamente mais tarde.")
+	}
+	if message != "" {
+		return slackText("Não foi possível solicitar as férias: " + message)
+	}
+
+	conflicts, err := services.OverlappingRequests(db, user.ID, nil, startDate, endDate)
+	if err != nil {
+		log.Printf("Failed to check overlapping requests of user %s: %v", user.ID, err)
+		return slackText("Não foi possível solicitar as férias. Tente novamente mais tarde.")
+	}
+	overlap := slackText("Você já tem férias solicitadas que coincidem com este período.")
+	if len(conflicts) > 0 {
+		return overlap
+	}
+
+	vacationRequest := models.VacationRequest{
+		UserID:       user.ID,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		BusinessDays: calculateBusinessDays(startDate, endDate),
+		Status:       models.StatusPending,
+		Reason:       strings.Join(args[2:], " "),
+		LeaveType:    models.LeaveTypeVacation,
+	}
+	err = createVacationRequest(db, user, &vacationRequest)
+	if services.IsOverlapViolation(err) {
+		return overlap
+	}
+	if err != nil {
+		log.Printf("Failed to create vacation request of user %s: %v", user.ID, err)
+		return slackText("Não foi possível solicitar as férias. Tente novamente mais tarde.")
+	}
+
+	// A team rule may have approved it right away
+	if err := db.Select("status").Where("id = ?", vacationRequest.ID).First(&vacationRequest).Error; err != nil {
+		log.Printf("Failed to reload vacation request %s: %v", vacationRequest.ID, err)
+	}
+	if vacationRequest.Status == models.StatusApproved {
+		return slackText(fmt.Sprintf("Suas férias de %s (%d dias úteis) foram aprovadas automaticamente.",
+			slackPeriod(&vacationRequest), vacationRequest.BusinessDays))
+	}
+	return slackText(fmt.Sprintf("Solicitação de férias de %s (%d dias úteis) enviada para aprovação.",
+		slackPeriod(&vacationRequest), vacationRequest.BusinessDays))
+}
+
+// slackTeam lists the upcoming absences of the caller's colleagues and
+// reports, followed by the requests awaiting the caller's decision with
+// buttons to decide them
+func slackTeam(db *gorm.DB, user *models.User) *models.SlackMessage {
+	failed := slackText("Não foi possível consultar a equipe. Tente novamente mais tarde.")
+	today := time.Now().Truncate(24 * time.Hour)
+
+	team := db.Model(&models.User{}).Select("id").Where("active = ? AND id <> ?", true, user.ID)
+	if user.ManagerID != nil {
+		team = team.Where("manager_id = ? OR manager_id = ?", *user.ManagerID, user.ID)
+	} else {
+		team = team.Where("manager_id = ?", user.ID)
+	}
+
+	var absences []models.VacationRequest
+	if err := db.Preload("User").
+		Where("user_id IN (?) AND status IN ? AND start_date <= ? AND end_date >= ?",
+			team, models.OnLeaveStatuses, today.AddDate(0, 0, slackTeamHorizonDays), today).
+		Order("start_date ASC").Find(&absences).Error; err != nil {
+		log.Printf("Failed to fetch team absences of user %s: %v", user.ID, err)
+		return failed
+	}
+
+	text := fmt.Sprintf("Ninguém da equipe estará ausente nos próximos %d dias.", slackTeamHorizonDays)
+	if len(absences) > 0 {
+		text = fmt.Sprintf("*Ausências da equipe nos próximos %d dias*", slackTeamHorizonDays)
+		for i := range absences {
+			text += fmt.Sprintf("\n• %s: %s", absences[i].User.Name, slackPeriod(&absences[i]))
+		}
+	}
+	reply := slackText(text)
+	reply.Blocks = []models.SlackBlock{slackSection(text)}
+
+	// Include the approvers the caller is currently standing in for
+	delegators, err := services.ActiveDelegators(db, user.ID, time.Now())
+	if err != nil {
+		log.Printf("Failed to resolve delegations of user %s: %v", user.ID, err)
+		return failed
+	}
+	approverIDs := append([]uuid.UUID{user.ID}, delegators...)
+
+	var pending []models.VacationRequest
+	if err := db.Preload("User").Preload("ApprovalSteps", orderedSteps).
+		Scopes(awaitingDecisionBy(approverIDs, string(user.Role))).
+		Where("vacation_requests.user_id <> ?", user.ID).
+		Order("vacation_requests.created_at ASC").
+		Limit(slackMaxPendingShown).Find(&pending).Error; err != nil {
+		log.Printf("Failed to fetch pending requests of user %s: %v", user.ID, err)
+		return failed
+	}
+	if len(pending) == 0 {
+		return reply
+	}
+
+	reply.Blocks = append(reply.Blocks, slackSection("*Aguardando sua decisão*"))
+	for i := range pending {
+		step := services.CurrentApprovalStep(pending[i].ApprovalSteps)
+		if step == nil {
+			continue
+		}
+		// The step ID keeps a stale button from deciding a later level
+		value := pending[i].ID.String() + ":" + step.ID.String()
+		reply.Blocks = append(reply.Blocks,
+			slackSection(fmt.Sprintf("*%s* - %s (%d dias úteis, etapa %d)",
+				pending[i].User.Name, slackPeriod(&pending[i]), pending[i].BusinessDays, step.Position)),
+			models.SlackBlock{
+				Type:    "actions",
+				BlockID: "decision:" + pending[i].ID.String(),
+				Elements: []models.SlackElement{
+					{Type: "button", Text: &models.SlackText{Type: "plain_text", Text: "Aprovar"}, ActionID: "approve", Value: value, Style: "primary"},
+					{Type: "button", Text: &models.SlackText{Type: "plain_text", Text: "Rejeitar"}, ActionID: "reject", Value: value, Style: "danger"},
+				},
+			})
+	}
+	return reply
+}
+
+// SlackInteraction applies the decision of an approve button, or of the
+// modal a reject button opens to ask for the reason, and posts the outcome
+// to the response URL of the message
+func SlackInteraction(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		values, ok := readSlackRequest(c)
+		if !ok {
+			return
+		}
+
+		var payload models.SlackInteraction
+		if err := json.Unmarshal([]byte(values.Get("payload")), &payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		var message, responseURL string
+		switch payload.Type {
+		case "block_actions":
+			if len(payload.Actions) == 0 {
+				break
+			}
+			action := payload.Actions[0]
+			if action.ActionID != "approve" && action.ActionID != "reject" {
+				break
+			}
+			responseURL = payload.ResponseURL
+
+			var user *models.User
+			user, message = slackUser(db, payload.Team.ID, payload.User.ID)
+			if message != "" {
+				break
+			}
+			if action.ActionID == "approve" {
+				message = slackDecide(db, user, true, action.Value, "")
+				break
+			}
+
+			// Rejections need a reason, asked for in a modal
+			if err := services.OpenSlackView(payload.TriggerID, slackRejectView(action.Value, payload.ResponseURL)); err != nil {
+				log.Printf("Failed to open slack rejection modal: %v", err)
+				message = "Não foi possível abrir o formulário de rejeição. Tente novamente mais tarde."
+			}
+
+		case "view_submission":
+			if payload.View.CallbackID != slackRejectCallbackID {
+				break
+			}
+			var metadata slackRejectMetadata
+			if err := json.Unmarshal([]byte(payload.View.PrivateMetadata), &metadata); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid request format",
+				})
+				return
+			}
+			reason := strings.TrimSpace(payload.View.State.Values["reason"]["reason"].Value)
+			if reason == "" {
+				c.JSON(http.StatusOK, gin.H{
+					"response_action": "errors",
+					"errors":          gin.H{"reason": "Informe o motivo da rejeição."},
+				})
+				return
+			}
+			responseURL = metadata.ResponseURL
+
+			var user *models.User
+			user, message = slackUser(db, payload.Team.ID, payload.User.ID)
+			if message == "" {
+				message = slackDecide(db, user, false, metadata.Value, reason)
+			}
+		}
+
+		// Slack expects the acknowledgement within three seconds; an empty
+		// one also closes the modal
+		c.Status(http.StatusOK)
+		if message != "" && responseURL != "" {
+			go func() {
+				if err := services.PostSlackResponse(responseURL, slackText(message)); err != nil {
+					log.Printf("Failed to post slack response: %v", err)
+				}
+			}()
+		}
+	}
+}
+
+// slackRejectView is the modal asking for the reason of a rejection
+func slackRejectView(value, responseURL string) *models.SlackView {
+	metadata, _ := json.Marshal(slackRejectMetadata{Value: value, ResponseURL: responseURL})
+	return &models.SlackView{
+		Type:            "modal",
+		CallbackID:      slackRejectCallbackID,
+		PrivateMetadata: string(metadata),
+		Title:           &models.SlackText{Type: "plain_text", Text: "Rejeitar férias"},
+		Submit:          &models.SlackText{Type: "plain_text", Text: "Rejeitar"},
+		Close:           &models.SlackText{Type: "plain_text", Text: "Cancelar"},
+		Blocks: []models.SlackBlock{{
+			Type:    "input",
+			BlockID: "reason",
+			Label:   &models.SlackText{Type: "plain_text", Text: "Motivo da rejeição"},
+			Element: &models.SlackElement{Type: "plain_text_input", ActionID: "reason", Multiline: true},
+		}},
+	}
+}
+
+// slackDecide records the caller's decision and describes the outcome
+func slackDecide(db *gorm.DB, user *models.User, approve bool, value, comment string) string {
+	requestIDStr, stepIDStr, _ := strings.Cut(value, ":")
+	requestID, err := uuid.Parse(requestIDStr)
+	if err != nil {
+		return "Ação inválida."
+	}
+	stepID, err := uuid.Parse(stepIDStr)
+	if err != nil {
+		return "Ação inválida."
+	}
+
+	result, err := services.DecideVacationRequest(db, services.DecisionInput{
+		RequestID: requestID,
+		StepID:    &stepID,
+		ActorID:   user.ID,
+		ActorRole: user.Role,
+		Approve:   approve,
+		Comment:   comment,
+		Source:    models.DecisionChat,
+	})
+	switch err {
+	case nil:
+	case services.ErrCommentRequired:
+		return "Informe o motivo da rejeição."
+	case services.ErrRequestNotFound:
+		return "Solicitação não encontrada."
+	case services.ErrInsufficientBalance:
+		return "O(a) colaborador(a) não tem mais saldo de férias suficiente para esta solicitação."
+	case services.ErrRequestNotPending, services.ErrNotApprover, services.ErrRequestChanged:
+		return "Esta solicitação não aguarda mais a sua decisão."
+	default:
+		log.Printf("Failed to decide vacation request %s from slack: %v", requestID, err)
+		return "Não foi possível registrar a decisão. Tente novamente mais tarde."
+	}
+
+	request := result.Request
+	switch {
+	case !approve:
+		return fmt.Sprintf("Você rejeitou as férias de %s (%s).", request.User.Name, slackPeriod(request))
+	case result.Final:
+		return fmt.Sprintf("Você aprovou as férias de %s (%s).", request.User.Name, slackPeriod(request))
+	default:
+		return fmt.Sprintf("Você aprovou a etapa %d das férias de %s (%s); a solicitação segue para a próxima etapa.",
+			result.Step.Position, request.User.Name, slackPeriod(request))
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gerenciador-ferias/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// streamKeepAlive keeps proxies from closing an idle stream
+const streamKeepAlive = 25 * time.Second
+
+// StreamEvents pushes the signed-in user's new notifications and request
+// status changes as Server-Sent Events. Reconnecting clients send the
+// Last-Event-ID header (or the last_event_id parameter) to receive what they
+// missed first.
+func StreamEvents(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		lastEventID := c.GetHeader("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.Query("last_event_id")
+		}
+		var afterID int64
+		if lastEventID != "" {
+			afterID, err = strconv.ParseInt(lastEventID, 10, 64)
+			if err != nil || afterID < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid last event ID",
+				})
+				return
+			}
+		}
+
+		// Subscribe before replaying so nothing published in between is lost
+		subscription, err := services.SubscribeStream(userID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Real-time stream is not available",
+			})
+			return
+		}
+		defer subscription.Close()
+
+		replayed := map[int64]bool{}
+		var missed []models.StreamEvent
+		if lastEventID != "" {
+			missed, err = services.ReplayStream(db, userID, afterID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to fetch missed events",
+				})
+				return
+			}
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		write := func(event models.StreamEvent) {
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Payload)
+		}
+
+		fmt.Fprint(c.Writer, "retry: 3000\n\n")
+		for _, event := range missed {
+			replayed[event.ID] = true
+			write(event)
+		}
+		c.Writer.Flush()
+
+		keepAlive := time.NewTicker(streamKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case event, ok := <-subscription.Events:
+				if !ok {
+					// Fell behind; the client reconnects and resumes
+					return
+				}
+				if replayed[event.ID] {
+					continue
+				}
+				write(event)
+			case <-keepAlive.C:
+				fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// CreateStreamTicket issues the short-lived ticket browsers use to open the
+// stream, since EventSource cannot send the Authorization header
+func CreateStreamTicket(jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		ticket, expiresAt, err := utils.GenerateStreamTicket(userID, c.GetString(middleware.UserEmailKey), c.GetString(middleware.UserRoleKey), jwtSecret)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create stream ticket",
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"ticket":     ticket,
+			"expires_at": expiresAt,
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// validateSubstitute checks that the chosen colleague can cover the requester
+// during the given period. A non-empty message means the substitute is not acceptable.
+func validateSubstitute(db *gorm.DB, requesterID, substituteID uuid.UUID, startDate, endDate time.Time) (string, error) {
+	if substituteID == requesterID {
+		return "You cannot be your own substitute", nil
+	}
+
+	var substitute models.User
+	if err := db.Where("id = ? AND active = ?", substituteID, true).First(&substitute).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "Substitute not found", nil
+		}
+		return "", err
+	}
+
+	onLeave, err := substituteOnLeave(db, substituteID, startDate, endDate)
+	if err != nil {
+		return "", err
+	}
+	if onLeave {
+		return "Substitute has approved vacation during this period", nil
+	}
+
+	return "", nil
+}
+
+// substituteOnLeave reports whether the user has approved vacation overlapping the period
+func substituteOnLeave(db *gorm.DB, substituteID uuid.UUID, startDate, endDate time.Time) (bool, error) {
+	var count int64
+	if err := db.Model(&models.VacationRequest{}).
+		Where("user_id = ? AND status IN ? AND start_date <= ? AND end_date >= ?",
+			substituteID, models.OnLeaveStatuses, endDate, startDate).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func GetSubstituteRequests(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		// Parse query parameters
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+		substituteStatus := c.Query("substitute_status")
+
+		if page < 1 {
+			page = 1
+		}
+		if perPage < 1 || perPage > 100 {
+			perPage = 10
+		}
+
+		offset := (page - 1) * perPage
+
+		// Only requests that are still active need coverage
+		query := db.Preload("User").Preload("Substitute").
+			Where("substitute_id = ? AND status IN ?", userID, models.ReservedStatuses)
+
+		if substituteStatus != "" {
+			query = query.Where("substitute_status = ?", substituteStatus)
+		}
+
+		// Count total
+		var total int64
+		if err := query.Model(&models.VacationRequest{}).Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to count substitute requests",
+			})
+			return
+		}
+
+		// Get requests
+		var requests []models.VacationRequest
+		if err := query.Order("start_date ASC").Offset(offset).Limit(perPage).Find(&requests).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch substitute requests",
+			})
+			return
+		}
+
+		// Convert to response format
+		var responseRequests []*models.VacationRequestResponse
+		for _, req := range requests {
+			responseRequests = append(responseRequests, req.ToResponse())
+		}
+
+		totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+
+		response := models.VacationRequestsListResponse{
+			Requests:   responseRequests,
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func AcceptSubstitution(db *gorm.DB) gin.HandlerFunc {
+	return respondToSubstitution(db, models.SubstituteAccepted)
+}
+
+func DeclineSubstitution(db *gorm.DB) gin.HandlerFunc {
+	return respondToSubstitution(db, models.SubstituteDeclined)
+}
+
+func respondToSubstitution(db *gorm.DB, decision models.SubstituteStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		requestIDStr := c.Param("id")
+		requestID, err := uuid.Parse(requestIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		var req models.SubstituteResponseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		var vacationRequest models.VacationRequest
+		if err := db.Preload("User").Preload("Substitute").
+			Where("id = ? AND substitute_id = ? AND status IN (?, ?)",
+				requestID, userID, models.StatusPending, models.StatusApproved).
+			First(&vacationRequest).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Vacation request not found or you are not its substitute",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vacation request",
+			})
+			return
+		}
+
+		if vacationRequest.SubstituteStatus != models.SubstitutePending {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Substitution has already been answered",
+			})
+			return
+		}
+
+		// The substitute cannot cover a period in which they are on leave themselves
+		if decision == models.SubstituteAccepted {
+			onLeave, err := substituteOnLeave(db, userID, vacationRequest.StartDate, vacationRequest.EndDate)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to check substitute availability",
+				})
+				return
+			}
+			if onLeave {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "You have approved vacation during this period",
+				})
+				return
+			}
+		}
+
+		now := time.Now()
+		previous := vacationRequest
+		vacationRequest.SubstituteStatus = decision
+		vacationRequest.SubstituteComment = req.Comment
+		vacationRequest.SubstituteRespondedAt = &now
+
+		action := models.HistorySubstituteAccepted
+		if decision == models.SubstituteDeclined {
+			action = models.HistorySubstituteDeclined
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := services.SaveVacationRequest(tx, &vacationRequest); err != nil {
+				return err
+			}
+			return services.RecordHistory(tx, models.RequestHistory{
+				VacationRequestID: vacationRequest.ID,
+				ActorID:           &userID,
+				Action:            action,
+				Comment:           req.Comment,
+			}, &previous, &vacationRequest)
+		})
+		if err == services.ErrRequestChanged {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Vacation request was changed by someone else, reload it and try again",
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to save substitute response",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, vacationRequest.ToResponse())
+	}
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gerenciador-ferias/backend/internal/middleware"
 	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -45,8 +48,8 @@ func GetVacationRequests(db *gorm.DB) gin.HandlerFunc {
 		offset := (page - 1) * perPage
 
 		// Build query
-		query := db.Preload("User").Preload("Approver").Where("user_id = ?", userID)
-		
+		query := db.Preload("User").Preload("Approver").Preload("Substitute").Where("user_id = ?", userID)
+
 		if status != "" {
 			query = query.Where("status = ?", status)
 		}
@@ -114,6 +117,9 @@ func CreateVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if req.LeaveType == "" {
+			req.LeaveType = models.LeaveTypeVacation
+		}
 
 		// Validate dates
 		if req.EndDate.Before(req.StartDate) {
@@ -123,58 +129,49 @@ func CreateVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Check minimum advance notice (15 days)
-		fifteenDaysFromNow := time.Now().AddDate(0, 0, 15)
-		if req.StartDate.Before(fifteenDaysFromNow) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Vacation requests must be made at least 15 days in advance",
-			})
-			return
-		}
-
-		// Calculate business days
-		businessDays := calculateBusinessDays(req.StartDate, req.EndDate)
-		if businessDays < 5 {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Minimum vacation period is 5 business days",
-			})
-			return
-		}
-
-		// Check user's vacation balance
-		var user models.User
-		if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+		user, err := loadUser(db, userID)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to fetch user information",
 			})
 			return
 		}
 
-		if user.VacationBalance < businessDays {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Insufficient vacation balance",
-			})
-			return
-		}
-
-		// Check for overlapping requests
-		var overlapping int64
-		if err := db.Model(&models.VacationRequest{}).
-			Where("user_id = ? AND status IN (?, ?) AND ((start_date <= ? AND end_date >= ?) OR (start_date <= ? AND end_date >= ?))",
-				userID, models.StatusPending, models.StatusApproved,
-				req.StartDate, req.StartDate, req.EndDate, req.EndDate).
-			Count(&overlapping).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to check for overlapping requests",
-			})
-			return
+		// Drafts are checked when they are submitted
+		if !req.Draft {
+			message, err := checkSubmission(db, user, req.LeaveType, req.StartDate, req.EndDate)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to validate vacation request",
+				})
+				return
+			}
+			if message != "" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": message,
+				})
+				return
+			}
+			if rejectOverlap(c, db, userID, nil, req.StartDate, req.EndDate) {
+				return
+			}
 		}
 
-		if overlapping > 0 {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "You have overlapping vacation requests",
-			})
-			return
+		// Validate the designated substitute, if any
+		if req.SubstituteID != nil {
+			message, err := validateSubstitute(db, userID, *req.SubstituteID, req.StartDate, req.EndDate)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to validate substitute",
+				})
+				return
+			}
+			if message != "" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": message,
+				})
+				return
+			}
 		}
 
 		// Create vacation request
@@ -182,13 +179,26 @@ func CreateVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			UserID:           userID,
 			StartDate:        req.StartDate,
 			EndDate:          req.EndDate,
-			BusinessDays:     businessDays,
+			BusinessDays:     calculateBusinessDays(req.StartDate, req.EndDate),
 			Status:           models.StatusPending,
 			Reason:           req.Reason,
 			EmergencyContact: req.EmergencyContact,
+			SubstituteID:     req.SubstituteID,
+			LeaveType:        req.LeaveType,
+		}
+		if req.SubstituteID != nil {
+			vacationRequest.SubstituteStatus = models.SubstitutePending
+		}
+		if req.Draft {
+			vacationRequest.Status = models.StatusDraft
 		}
 
-		if err := db.Create(&vacationRequest).Error; err != nil {
+		err = createVacationRequest(db, user, &vacationRequest)
+		if services.IsOverlapViolation(err) {
+			respondOverlapViolation(c, db, &vacationRequest)
+			return
+		}
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to create vacation request",
 			})
@@ -196,16 +206,13 @@ func CreateVacationRequest(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		// Load user and approver for response
-		if err := db.Preload("User").Preload("Approver").First(&vacationRequest, vacationRequest.ID).Error; err != nil {
+		if err := db.Preload("User").Preload("Approver").Preload("Substitute").Preload("ApprovalSteps", orderedSteps).First(&vacationRequest, vacationRequest.ID).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to load vacation request details",
 			})
 			return
 		}
 
-		// TODO: Create notification for manager
-		// TODO: Send email notification
-
 		c.JSON(http.StatusCreated, vacationRequest.ToResponse())
 	}
 }
@@ -238,7 +245,7 @@ func GetVacationRequest(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		var vacationRequest models.VacationRequest
-		if err := db.Preload("User").Preload("Approver").
+		if err := db.Preload("User").Preload("Approver").Preload("Substitute").Preload("ApprovalSteps", orderedSteps).
 			Where("id = ? AND user_id = ?", requestID, userID).
 			First(&vacationRequest).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
@@ -299,10 +306,10 @@ func UpdateVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Only pending requests can be updated
-		if vacationRequest.Status != models.StatusPending {
+		// Only draft and pending requests can be updated
+		if !vacationRequest.Editable() {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Only pending requests can be updated",
+				"error": "Only draft or pending requests can be updated",
 			})
 			return
 		}
@@ -315,6 +322,18 @@ func UpdateVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		if req.Version != nil && *req.Version != vacationRequest.Version {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Vacation request was changed by someone else, reload it and try again",
+			})
+			return
+		}
+
+		previous := vacationRequest
+		previousStart := vacationRequest.StartDate
+		previousEnd := vacationRequest.EndDate
+		previousSubstitute := vacationRequest.SubstituteID
+
 		// Update fields if provided
 		if req.StartDate != nil {
 			vacationRequest.StartDate = *req.StartDate
@@ -322,6 +341,9 @@ func UpdateVacationRequest(db *gorm.DB) gin.HandlerFunc {
 		if req.EndDate != nil {
 			vacationRequest.EndDate = *req.EndDate
 		}
+		if req.SubstituteID.Set {
+			vacationRequest.SubstituteID = req.SubstituteID.Value
+		}
 		if req.Reason != nil {
 			vacationRequest.Reason = *req.Reason
 		}
@@ -337,10 +359,124 @@ func UpdateVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		// Drafts hold no days until they are submitted
+		periodChanged := !previousStart.Equal(vacationRequest.StartDate) || !previousEnd.Equal(vacationRequest.EndDate)
+		if periodChanged && vacationRequest.Status == models.StatusPending &&
+			rejectOverlap(c, db, userID, &vacationRequest.ID, vacationRequest.StartDate, vacationRequest.EndDate) {
+			return
+		}
+
 		// Recalculate business days
+		previousBusinessDays := vacationRequest.BusinessDays
 		vacationRequest.BusinessDays = calculateBusinessDays(vacationRequest.StartDate, vacationRequest.EndDate)
 
-		if err := db.Save(&vacationRequest).Error; err != nil {
+		// A new substitute or a new period requires the coverage to be confirmed again
+		substituteChanged := vacationRequest.SubstituteID != nil &&
+			(previousSubstitute == nil || *previousSubstitute != *vacationRequest.SubstituteID || periodChanged)
+		if substituteChanged {
+			message, err := validateSubstitute(db, userID, *vacationRequest.SubstituteID, vacationRequest.StartDate, vacationRequest.EndDate)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to validate substitute",
+				})
+				return
+			}
+			if message != "" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": message,
+				})
+				return
+			}
+			vacationRequest.SubstituteStatus = models.SubstitutePending
+			vacationRequest.SubstituteComment = ""
+			vacationRequest.SubstituteRespondedAt = nil
+		}
+		if vacationRequest.SubstituteID == nil {
+			vacationRequest.SubstituteStatus = ""
+			vacationRequest.SubstituteComment = ""
+			vacationRequest.SubstituteRespondedAt = nil
+		}
+
+		var rejection string
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// Approvals already given were for the old period
+			if periodChanged && vacationRequest.Status == models.StatusPending {
+				decidedSteps, err := services.LockDecidedSteps(tx, vacationRequest.ID)
+				if err != nil {
+					return err
+				}
+				if decidedSteps > 0 {
+					return services.ErrApprovalStarted
+				}
+
+				// The new period must meet the same rules as a submission
+				user, err := loadUser(tx, userID)
+				if err != nil {
+					return err
+				}
+				rejection, err = checkSubmission(tx, user, vacationRequest.LeaveType, vacationRequest.StartDate, vacationRequest.EndDate)
+				if err != nil {
+					return err
+				}
+				if rejection != "" {
+					return errPeriodRejected
+				}
+			}
+
+			if err := services.SaveVacationRequest(tx, &vacationRequest); err != nil {
+				return err
+			}
+
+			if err := services.RecordHistory(tx, models.RequestHistory{
+				VacationRequestID: vacationRequest.ID,
+				ActorID:           &userID,
+				Action:            models.HistoryUpdated,
+			}, &previous, &vacationRequest); err != nil {
+				return err
+			}
+
+			// A different duration may match another approval rule; nobody has
+			// decided on the chain yet, so it is rebuilt
+			if vacationRequest.Status != models.StatusPending || vacationRequest.BusinessDays == previousBusinessDays {
+				return nil
+			}
+
+			var requester models.User
+			if err := tx.Where("id = ?", vacationRequest.UserID).First(&requester).Error; err != nil {
+				return err
+			}
+			steps, err := services.BuildApprovalSteps(tx, &requester, &vacationRequest)
+			if err != nil {
+				return err
+			}
+			if err := tx.Where("vacation_request_id = ?", vacationRequest.ID).Delete(&models.ApprovalStep{}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&steps).Error
+		})
+		if err == services.ErrRequestChanged {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Vacation request was changed by someone else, reload it and try again",
+			})
+			return
+		}
+		if err == errPeriodRejected {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": rejection,
+			})
+			return
+		}
+		if err == services.ErrApprovalStarted {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "The period cannot change after an approval level has decided; cancel the request and create a new one",
+			})
+			return
+		}
+		if services.IsOverlapViolation(err) {
+			respondOverlapViolation(c, db, &vacationRequest)
+			return
+		}
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to update vacation request",
 			})
@@ -348,7 +484,7 @@ func UpdateVacationRequest(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		// Load related data for response
-		if err := db.Preload("User").Preload("Approver").First(&vacationRequest, vacationRequest.ID).Error; err != nil {
+		if err := db.Preload("User").Preload("Approver").Preload("Substitute").Preload("ApprovalSteps", orderedSteps).First(&vacationRequest, vacationRequest.ID).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to load vacation request details",
 			})
@@ -388,7 +524,7 @@ func DeleteVacationRequest(db *gorm.DB) gin.HandlerFunc {
 
 		// Find the vacation request
 		var vacationRequest models.VacationRequest
-		if err := db.Where("id = ? AND user_id = ?", requestID, userID).First(&vacationRequest).Error; err != nil {
+		if err := db.Preload("User").Where("id = ? AND user_id = ?", requestID, userID).First(&vacationRequest).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": "Vacation request not found",
@@ -401,17 +537,37 @@ func DeleteVacationRequest(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Only pending requests can be cancelled
-		if vacationRequest.Status != models.StatusPending {
+		// Update status to cancelled instead of deleting
+		previous := vacationRequest
+		if _, err := vacationRequest.Apply(models.EventCancel, time.Now()); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Only pending requests can be cancelled",
+				"error": "Only draft or pending requests can be cancelled",
 			})
 			return
 		}
 
-		// Update status to cancelled instead of deleting
-		vacationRequest.Status = models.StatusCancelled
-		if err := db.Save(&vacationRequest).Error; err != nil {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := services.SaveVacationRequest(tx, &vacationRequest); err != nil {
+				return err
+			}
+			if err := services.RecordHistory(tx, models.RequestHistory{
+				VacationRequestID: vacationRequest.ID,
+				ActorID:           &userID,
+				Action:            models.HistoryCancelled,
+			}, &previous, &vacationRequest); err != nil {
+				return err
+			}
+			return tx.Model(&models.ApprovalStep{}).
+				Where("vacation_request_id = ? AND status = ?", vacationRequest.ID, models.StepPending).
+				Update("status", models.StepSkipped).Error
+		})
+		if err == services.ErrRequestChanged {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Vacation request was changed by someone else, reload it and try again",
+			})
+			return
+		}
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to cancel vacation request",
 			})
@@ -458,12 +614,12 @@ func GetVacationRequestStats(db *gorm.DB) gin.HandlerFunc {
 
 		// Count by status
 		db.Model(&models.VacationRequest{}).Where("user_id = ? AND status = ?", userID, "pending").Count(&stats.PendingRequests)
-		db.Model(&models.VacationRequest{}).Where("user_id = ? AND status = ?", userID, "approved").Count(&stats.ApprovedRequests)
+		db.Model(&models.VacationRequest{}).Where("user_id = ? AND status IN ?", userID, models.GrantedStatuses).Count(&stats.ApprovedRequests)
 		db.Model(&models.VacationRequest{}).Where("user_id = ? AND status = ?", userID, "rejected").Count(&stats.RejectedRequests)
 
 		// Calculate total days used (approved requests)
 		var approvedRequests []models.VacationRequest
-		db.Where("user_id = ? AND status = ?", userID, "approved").Find(&approvedRequests)
+		db.Where("user_id = ? AND status IN ?", userID, models.GrantedStatuses).Find(&approvedRequests)
 		for _, req := range approvedRequests {
 			stats.TotalDaysUsed += req.BusinessDays
 		}
@@ -479,6 +635,335 @@ func GetVacationRequestStats(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// loadUser fetches the user a request belongs to
+func loadUser(db *gorm.DB, userID uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// errPeriodRejected aborts an update whose new period fails checkSubmission
+var errPeriodRejected = errors.New("vacation period does not meet the submission rules")
+
+// checkSubmission applies the rules a request must meet to enter approval.
+// A non-empty message means the request cannot be submitted. Overlaps are
+// checked separately by rejectOverlap.
+func checkSubmission(db *gorm.DB, user *models.User, leaveType models.LeaveType, startDate, endDate time.Time) (string, error) {
+	// Check minimum advance notice (15 days)
+	fifteenDaysFromNow := time.Now().AddDate(0, 0, 15)
+	if startDate.Before(fifteenDaysFromNow) {
+		return "Vacation requests must be made at least 15 days in advance", nil
+	}
+
+	// Calculate business days
+	businessDays := calculateBusinessDays(startDate, endDate)
+	if businessDays < 5 {
+		return "Minimum vacation period is 5 business days", nil
+	}
+
+	// Check user's vacation balance; other leave types do not use it
+	if leaveType == models.LeaveTypeVacation && user.VacationBalance < businessDays {
+		return "Insufficient vacation balance", nil
+	}
+
+	return "", nil
+}
+
+// rejectOverlap answers with the requester's reserved requests sharing days
+// with the period and reports whether there were any
+func rejectOverlap(c *gin.Context, db *gorm.DB, userID uuid.UUID, excludeID *uuid.UUID, startDate, endDate time.Time) bool {
+	conflicts, err := services.OverlappingRequests(db, userID, excludeID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check overlapping requests",
+		})
+		return true
+	}
+	if len(conflicts) == 0 {
+		return false
+	}
+
+	responses := make([]*models.VacationRequestResponse, 0, len(conflicts))
+	for i := range conflicts {
+		responses = append(responses, conflicts[i].ToResponse())
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error":     "You have overlapping vacation requests",
+		"conflicts": responses,
+	})
+	return true
+}
+
+// respondOverlapViolation answers a write the database refused for
+// overlapping a request saved concurrently
+func respondOverlapViolation(c *gin.Context, db *gorm.DB, vacationRequest *models.VacationRequest) {
+	if !rejectOverlap(c, db, vacationRequest.UserID, &vacationRequest.ID, vacationRequest.StartDate, vacationRequest.EndDate) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "You have overlapping vacation requests",
+		})
+	}
+}
+
+// createVacationRequest stores a new request of the user together with its
+// approval chain and, when it is not a draft, starts the approval
+func createVacationRequest(db *gorm.DB, user *models.User, vacationRequest *models.VacationRequest) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(vacationRequest).Error; err != nil {
+			return err
+		}
+
+		if err := services.RecordHistory(tx, models.RequestHistory{
+			VacationRequestID: vacationRequest.ID,
+			ActorID:           &user.ID,
+			Action:            models.HistoryCreated,
+			ToStatus:          vacationRequest.Status,
+		}, nil, nil); err != nil {
+			return err
+		}
+
+		if vacationRequest.Status == models.StatusDraft {
+			return nil
+		}
+		if err := createApprovalChain(tx, user, vacationRequest); err != nil {
+			return err
+		}
+		startApproval(tx, user, vacationRequest)
+		return nil
+	})
+}
+
+// createApprovalChain stores the approval steps of a request entering approval
+func createApprovalChain(tx *gorm.DB, user *models.User, vacationRequest *models.VacationRequest) error {
+	steps, err := services.BuildApprovalSteps(tx, user, vacationRequest)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&steps).Error
+}
+
+// startApproval applies the team auto-approval rules to a request entering
+// approval. It runs in the transaction that submits the request, so the
+// approver and substitute notices sent through the outbox see the outcome;
+// a failed evaluation is rolled back to a savepoint and leaves the request
+// pending.
+func startApproval(tx *gorm.DB, user *models.User, vacationRequest *models.VacationRequest) {
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		rule, err := services.FindAutoApprovalRule(tx, user, vacationRequest)
+		if err != nil || rule == nil {
+			return err
+		}
+		return services.AutoApprove(tx, vacationRequest, rule)
+	})
+	if err != nil {
+		log.Printf("Failed to auto-approve request %s: %v", vacationRequest.ID, err)
+	}
+}
+
+func SubmitVacationRequest(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		requestID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		var vacationRequest models.VacationRequest
+		if err := db.Where("id = ? AND user_id = ?", requestID, userID).First(&vacationRequest).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Vacation request not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vacation request",
+			})
+			return
+		}
+
+		previous := vacationRequest
+		if _, err := vacationRequest.Apply(models.EventSubmit, time.Now()); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Only draft requests can be submitted",
+			})
+			return
+		}
+
+		user, err := loadUser(db, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch user information",
+			})
+			return
+		}
+
+		message, err := checkSubmission(db, user, vacationRequest.LeaveType, vacationRequest.StartDate, vacationRequest.EndDate)
+		if err == nil && message == "" && vacationRequest.SubstituteID != nil {
+			message, err = validateSubstitute(db, userID, *vacationRequest.SubstituteID, vacationRequest.StartDate, vacationRequest.EndDate)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to validate vacation request",
+			})
+			return
+		}
+		if message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+		if rejectOverlap(c, db, userID, &vacationRequest.ID, vacationRequest.StartDate, vacationRequest.EndDate) {
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := services.SaveVacationRequest(tx, &vacationRequest); err != nil {
+				return err
+			}
+			if err := services.RecordHistory(tx, models.RequestHistory{
+				VacationRequestID: vacationRequest.ID,
+				ActorID:           &userID,
+				Action:            models.HistorySubmitted,
+			}, &previous, &vacationRequest); err != nil {
+				return err
+			}
+			if err := createApprovalChain(tx, user, &vacationRequest); err != nil {
+				return err
+			}
+			startApproval(tx, user, &vacationRequest)
+			return nil
+		})
+		if err == services.ErrRequestChanged {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Vacation request was changed by someone else, reload it and try again",
+			})
+			return
+		}
+		if services.IsOverlapViolation(err) {
+			respondOverlapViolation(c, db, &vacationRequest)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to submit vacation request",
+			})
+			return
+		}
+
+		if err := db.Preload("User").Preload("Approver").Preload("Substitute").Preload("ApprovalSteps", orderedSteps).First(&vacationRequest, vacationRequest.ID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load vacation request details",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, vacationRequest.ToResponse())
+	}
+}
+
+func InterruptVacationRequest(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		userRole, _ := c.Get(middleware.UserRoleKey)
+		userRoleStr, _ := userRole.(string)
+
+		requestID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request ID format",
+			})
+			return
+		}
+
+		var req models.InterruptVacationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+
+		vacationRequest, err := services.InterruptLeave(db, requestID, userID, models.UserRole(userRoleStr), req.ReturnDate, req.Comment)
+		if err != nil {
+			var transitionErr *models.TransitionError
+			switch {
+			case err == services.ErrRequestNotFound || err == services.ErrNotAllowedToInterrupt:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Vacation request not found",
+				})
+			case errors.As(err, &transitionErr) && transitionErr.Reason != "":
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Return date must be after the start date and within the vacation period",
+				})
+			case errors.As(err, &transitionErr):
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Only vacations in progress can be interrupted",
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to interrupt vacation request",
+				})
+			}
+			return
+		}
+
+		if err := db.Preload("User").Preload("Approver").Preload("Substitute").Preload("ApprovalSteps", orderedSteps).First(vacationRequest, vacationRequest.ID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load vacation request details",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, vacationRequest.ToResponse())
+	}
+}
+
+// orderedSteps preloads approval steps in chain order
+func orderedSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+// chronologicalHistory preloads the request timeline oldest first
+func chronologicalHistory(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC")
+}
+
 func calculateBusinessDays(start, end time.Time) int {
 	days := 0
 	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
@@ -487,4 +972,4 @@ func calculateBusinessDays(start, end time.Time) int {
 		}
 	}
 	return days
-}
\ No newline at end of file
+}
@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gerenciador-ferias/backend/internal/middleware"
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/gerenciador-ferias/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// validateWebhookEvents rejects events subscribers cannot receive
+func validateWebhookEvents(events []string) string {
+	known := map[string]bool{}
+	for _, event := range models.WebhookEvents {
+		known[event] = true
+	}
+	for _, event := range events {
+		if !known[event] {
+			return "Unknown webhook event " + event
+		}
+	}
+	return ""
+}
+
+// findWebhookSubscription loads the subscription of the :id parameter,
+// responding with the error when it cannot
+func findWebhookSubscription(c *gin.Context, db *gorm.DB) *models.WebhookSubscription {
+	subscriptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid webhook ID format",
+		})
+		return nil
+	}
+
+	var subscription models.WebhookSubscription
+	if err := db.Where("id = ?", subscriptionID).First(&subscription).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Webhook not found",
+			})
+			return nil
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch webhook",
+		})
+		return nil
+	}
+	return &subscription
+}
+
+func GetWebhooks(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var subscriptions []models.WebhookSubscription
+		if err := db.Order("created_at ASC").Find(&subscriptions).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch webhooks",
+			})
+			return
+		}
+
+		response := make([]*models.WebhookSubscriptionResponse, 0, len(subscriptions))
+		for i := range subscriptions {
+			response = append(response, subscriptions[i].ToResponse())
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"webhooks": response,
+			"events":   models.WebhookEvents,
+			"total":    len(response),
+		})
+	}
+}
+
+// CreateWebhook registers a subscription. The secret is only returned here
+// and when it is changed.
+func CreateWebhook(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := c.Get(middleware.UserIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User ID not found in context",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID format",
+			})
+			return
+		}
+
+		var req models.CreateWebhookSubscriptionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+		if message := validateWebhookEvents(req.Events); message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		secret := req.Secret
+		if secret == "" {
+			secret, err = services.GenerateWebhookSecret()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to generate webhook secret",
+				})
+				return
+			}
+		} else if len(secret) < 16 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "The secret must have at least 16 characters",
+			})
+			return
+		}
+
+		subscription := models.WebhookSubscription{
+			URL:         req.URL,
+			Description: req.Description,
+			Secret:      secret,
+			Active:      req.Active == nil || *req.Active,
+			CreatedBy:   &userID,
+		}
+		if err := subscription.SetEvents(req.Events); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create webhook",
+			})
+			return
+		}
+		if err := db.Create(&subscription).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create webhook",
+			})
+			return
+		}
+
+		response := subscription.ToResponse()
+		response.Secret = subscription.Secret
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func UpdateWebhook(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.UpdateWebhookSubscriptionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+		if message := validateWebhookEvents(req.Events); message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		subscription := findWebhookSubscription(c, db)
+		if subscription == nil {
+			return
+		}
+
+		if req.URL != nil {
+			subscription.URL = *req.URL
+		}
+		if req.Description != nil {
+			subscription.Description = *req.Description
+		}
+		if req.Events != nil {
+			if err := subscription.SetEvents(req.Events); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to update webhook",
+				})
+				return
+			}
+		}
+		if req.Secret != nil {
+			subscription.Secret = *req.Secret
+		}
+		if req.Active != nil {
+			subscription.Active = *req.Active
+		}
+
+		if err := db.Save(subscription).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update webhook",
+			})
+			return
+		}
+
+		response := subscription.ToResponse()
+		if req.Secret != nil {
+			response.Secret = subscription.Secret
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func DeleteWebhook(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscription := findWebhookSubscription(c, db)
+		if subscription == nil {
+			return
+		}
+
+		// The delivery log goes with the subscription
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("subscription_id = ?", subscription.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(subscription).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to delete webhook",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Webhook deleted successfully",
+		})
+	}
+}
+
+// GetWebhookDeliveries lists the delivery log of a subscription, newest
+// first, optionally filtered by status
+func GetWebhookDeliveries(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscription := findWebhookSubscription(c, db)
+		if subscription == nil {
+			return
+		}
+
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+
+		if page < 1 {
+			page = 1
+		}
+		if perPage < 1 || perPage > 100 {
+			perPage = 20
+		}
+
+		offset := (page - 1) * perPage
+
+		query := db.Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscription.ID)
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to count webhook deliveries",
+			})
+			return
+		}
+
+		var deliveries []models.WebhookDelivery
+		if err := query.Order("created_at DESC").Offset(offset).Limit(perPage).Find(&deliveries).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch webhook deliveries",
+			})
+			return
+		}
+
+		response := make([]*models.WebhookDeliveryResponse, 0, len(deliveries))
+		for i := range deliveries {
+			response = append(response, deliveries[i].ToResponse())
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"deliveries":  response,
+			"total":       total,
+			"page":        page,
+			"per_page":    perPage,
+			"total_pages": int((total + int64(perPage) - 1) / int64(perPage)),
+		})
+	}
+}
+
+// RedeliverWebhook sends the event of a logged delivery again as a new
+// delivery, keeping the original in the log
+func RedeliverWebhook(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscription := findWebhookSubscription(c, db)
+		if subscription == nil {
+			return
+		}
+
+		deliveryID, err := uuid.Parse(c.Param("deliveryId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid delivery ID format",
+			})
+			return
+		}
+
+		var delivery models.WebhookDelivery
+		if err := db.Where("id = ? AND subscription_id = ?", deliveryID, subscription.ID).First(&delivery).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Webhook delivery not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch webhook delivery",
+			})
+			return
+		}
+
+		if !subscription.Active {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Webhook is inactive",
+			})
+			return
+		}
+
+		redelivery, err := services.Redeliver(db, &delivery)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to queue redelivery",
+			})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, redelivery.ToResponse())
+	}
+}
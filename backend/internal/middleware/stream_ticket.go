@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gerenciador-ferias/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamAuth authenticates the real-time stream. Browsers, whose EventSource
+// cannot send headers, pass a stream ticket in the ticket query parameter;
+// other clients send the usual Authorization header. Access tokens are never
+// accepted in the query string, where they would end up in access logs.
+func StreamAuth(jwtSecret string) gin.HandlerFunc {
+	authenticate := AuthMiddleware(jwtSecret)
+
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			authenticate(c)
+			return
+		}
+
+		claims, err := utils.ValidateStreamTicket(ticket, jwtSecret)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid or expired stream ticket",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(UserIDKey, claims.UserID.String())
+		c.Set(UserEmailKey, claims.Email)
+		c.Set(UserRoleKey, claims.Role)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TokenAction string
+
+const (
+	TokenApprove TokenAction = "approve"
+	TokenReject  TokenAction = "reject"
+)
+
+// ActionToken lets an approver decide one approval step from a link, without
+// logging in. The link carries the token ID and an HMAC signature over it;
+// tokens expire and are consumed together with their sibling on first use.
+type ActionToken struct {
+	ID                uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	VacationRequestID uuid.UUID   `json:"vacation_request_id" gorm:"type:uuid;not null;index"`
+	ApprovalStepID    uuid.UUID   `json:"approval_step_id" gorm:"type:uuid;not null;index"`
+	UserID            uuid.UUID   `json:"user_id" gorm:"type:uuid;not null"`
+	Action            TokenAction `json:"action" gorm:"type:varchar(20);not null"`
+	ExpiresAt         time.Time   `json:"expires_at" gorm:"not null"`
+	UsedAt            *time.Time  `json:"used_at"`
+	CreatedAt         time.Time   `json:"created_at"`
+}
+
+func (ActionToken) TableName() string {
+	return "action_tokens"
+}
+
+func (t *ActionToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+type ActionLinkDecisionRequest struct {
+	Comment string `json:"comment" form:"comment"`
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ApproverType string
+
+const (
+	// ApproverManager is the requester's direct manager
+	ApproverManager ApproverType = "manager"
+	// ApproverSkipManager is the manager of the requester's manager (e.g. a director)
+	ApproverSkipManager ApproverType = "skip_manager"
+	// ApproverUser is a specific user, e.g. the HR business partner
+	ApproverUser ApproverType = "user"
+	// ApproverRole is any user holding the given role
+	ApproverRole ApproverType = "role"
+)
+
+type ApprovalStepStatus string
+
+const (
+	StepPending  ApprovalStepStatus = "pending"
+	StepApproved ApprovalStepStatus = "approved"
+	StepRejected ApprovalStepStatus = "rejected"
+	StepSkipped  ApprovalStepStatus = "skipped"
+)
+
+type DecisionSource string
+
+const (
+	DecisionManual DecisionSource = "manual"
+	DecisionSystem DecisionSource = "system"
+	// DecisionLink is a decision made through a signed link from a notification
+	DecisionLink DecisionSource = "link"
+	// DecisionChat is a decision made from a button in a chat message
+	DecisionChat DecisionSource = "chat"
+)
+
+// ApprovalRule defines the approval chain applied to the requests it matches.
+// Empty criteria match everything; the active rule with the highest priority wins.
+type ApprovalRule struct {
+	ID              uuid.UUID          `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name            string             `json:"name" gorm:"not null"`
+	Priority        int                `json:"priority" gorm:"not null;default:0"`
+	Active          bool               `json:"active" gorm:"default:true"`
+	MinBusinessDays int                `json:"min_business_days" gorm:"not null;default:0"`
+	Department      string             `json:"department"`
+	LeaveType       LeaveType          `json:"leave_type" gorm:"type:varchar(20)"`
+	Steps           []ApprovalRuleStep `json:"steps" gorm:"foreignKey:RuleID;constraint:OnDelete:CASCADE"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+func (ApprovalRule) TableName() string {
+	return "approval_rules"
+}
+
+func (r *ApprovalRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to a request with the given attributes
+func (r *ApprovalRule) Matches(businessDays int, department string, leaveType LeaveType) bool {
+	if !r.Active || businessDays < r.MinBusinessDays {
+		return false
+	}
+	if r.Department != "" && r.Department != department {
+		return false
+	}
+	return r.LeaveType == "" || r.LeaveType == leaveType
+}
+
+type ApprovalRuleStep struct {
+	ID             uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	RuleID         uuid.UUID    `json:"rule_id" gorm:"type:uuid;not null;index"`
+	Position       int          `json:"position" gorm:"not null"`
+	ApproverType   ApproverType `json:"approver_type" gorm:"type:varchar(20);not null"`
+	ApproverUserID *uuid.UUID   `json:"approver_user_id" gorm:"type:uuid"`
+	ApproverRole   UserRole     `json:"approver_role" gorm:"type:varchar(20)"`
+}
+
+func (ApprovalRuleStep) TableName() string {
+	return "approval_rule_steps"
+}
+
+func (s *ApprovalRuleStep) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// ApprovalStep is one level of the approval chain of a specific request
+type ApprovalStep struct {
+	ID                uuid.UUID          `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	VacationRequestID uuid.UUID          `json:"vacation_request_id" gorm:"type:uuid;not null;index"`
+	Position          int                `json:"position" gorm:"not null"`
+	ApproverType      ApproverType       `json:"approver_type" gorm:"type:varchar(20);not null"`
+	ApproverID        *uuid.UUID         `json:"approver_id" gorm:"type:uuid;index"`
+	Approver          *User              `json:"approver,omitempty" gorm:"foreignKey:ApproverID"`
+	ApproverRole      UserRole           `json:"approver_role" gorm:"type:varchar(20)"`
+	Status            ApprovalStepStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	DecidedBy         *uuid.UUID         `json:"decided_by" gorm:"type:uuid"`
+	OnBehalfOf        *uuid.UUID         `json:"on_behalf_of" gorm:"type:uuid"`
+	DecisionSource    DecisionSource     `json:"decision_source" gorm:"type:varchar(20)"`
+	ActivatedAt       *time.Time         `json:"activated_at"`
+	RemindersSent     int                `json:"reminders_sent" gorm:"not null;default:0"`
+	EscalatedAt       *time.Time         `json:"escalated_at"`
+	EscalatedFromID   *uuid.UUID         `json:"escalated_from_id" gorm:"type:uuid"`
+	DecidedAt         *time.Time         `json:"decided_at"`
+	Comment           string             `json:"comment"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+func (ApprovalStep) TableName() string {
+	return "approval_steps"
+}
+
+func (s *ApprovalStep) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// CanBeDecidedBy reports whether the user is the approver assigned to the step.
+// Admins may also decide steps assigned to HR.
+func (s *ApprovalStep) CanBeDecidedBy(userID uuid.UUID, role UserRole) bool {
+	if s.ApproverID != nil {
+		return *s.ApproverID == userID
+	}
+	if s.ApproverRole == RoleHR && role == RoleAdmin {
+		return true
+	}
+	return s.ApproverRole != "" && s.ApproverRole == role
+}
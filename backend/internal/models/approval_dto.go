@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ApprovalRuleStepInput struct {
+	ApproverType   ApproverType `json:"approver_type" binding:"required,oneof=manager skip_manager user role"`
+	ApproverUserID *uuid.UUID   `json:"approver_user_id,omitempty"`
+	ApproverRole   UserRole     `json:"approver_role,omitempty" binding:"omitempty,oneof=employee manager admin hr"`
+}
+
+type ApprovalRuleRequest struct {
+	Name            string                  `json:"name" binding:"required"`
+	Priority        int                     `json:"priority"`
+	Active          *bool                   `json:"active,omitempty"`
+	MinBusinessDays int                     `json:"min_business_days" binding:"min=0"`
+	Department      string                  `json:"department"`
+	LeaveType       LeaveType               `json:"leave_type" binding:"omitempty,oneof=vacation unpaid compensatory"`
+	Steps           []ApprovalRuleStepInput `json:"steps" binding:"required,min=1,dive"`
+}
+
+type ApprovalStepResponse struct {
+	ID            string        `json:"id"`
+	Position      int           `json:"position"`
+	ApproverType  string        `json:"approver_type"`
+	ApproverID    *string       `json:"approver_id,omitempty"`
+	Approver      *UserResponse `json:"approver,omitempty"`
+	ApproverRole  string        `json:"approver_role,omitempty"`
+	Status        string        `json:"status"`
+	DecidedBy     *string       `json:"decided_by,omitempty"`
+	OnBehalfOf    *string       `json:"on_behalf_of,omitempty"`
+	Source        string        `json:"decision_source,omitempty"`
+	ActivatedAt   *time.Time    `json:"activated_at,omitempty"`
+	EscalatedAt   *time.Time    `json:"escalated_at,omitempty"`
+	EscalatedFrom *string       `json:"escalated_from_id,omitempty"`
+	DecidedAt     *time.Time    `json:"decided_at,omitempty"`
+	Comment       string        `json:"comment"`
+}
+
+func (s *ApprovalStep) ToResponse() *ApprovalStepResponse {
+	response := &ApprovalStepResponse{
+		ID:           s.ID.String(),
+		Position:     s.Position,
+		ApproverType: string(s.ApproverType),
+		ApproverRole: string(s.ApproverRole),
+		Status:       string(s.Status),
+		DecidedAt:    s.DecidedAt,
+		Comment:      s.Comment,
+		Source:       string(s.DecisionSource),
+		ActivatedAt:  s.ActivatedAt,
+		EscalatedAt:  s.EscalatedAt,
+	}
+
+	if s.ApproverID != nil {
+		approverIDStr := s.ApproverID.String()
+		response.ApproverID = &approverIDStr
+		if s.Approver != nil && s.Approver.ID != uuid.Nil {
+			response.Approver = s.Approver.ToResponse()
+		}
+	}
+
+	if s.DecidedBy != nil {
+		decidedByStr := s.DecidedBy.String()
+		response.DecidedBy = &decidedByStr
+	}
+
+	if s.EscalatedFromID != nil {
+		escalatedFromStr := s.EscalatedFromID.String()
+		response.EscalatedFrom = &escalatedFromStr
+	}
+
+	if s.OnBehalfOf != nil {
+		onBehalfOfStr := s.OnBehalfOf.String()
+		response.OnBehalfOf = &onBehalfOfStr
+	}
+
+	return response
+}
@@ -24,6 +24,7 @@ type UserResponse struct {
 	Role            string    `json:"role"`
 	VacationBalance int       `json:"vacation_balance"`
 	Department      string    `json:"department"`
+	Locale          string    `json:"locale"`
 	Manager         *Manager  `json:"manager,omitempty"`
 }
 
@@ -41,6 +42,7 @@ func (u *User) ToResponse() *UserResponse {
 		Role:            string(u.Role),
 		VacationBalance: u.VacationBalance,
 		Department:      u.Department,
+		Locale:          u.Locale,
 	}
 
 	if u.Manager != nil {
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AutoApprovalRule lets a manager approve low-risk requests of their team
+// automatically. Zero-valued limits are not enforced.
+type AutoApprovalRule struct {
+	ID                uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ManagerID         uuid.UUID `json:"manager_id" gorm:"type:uuid;not null;index"`
+	Name              string    `json:"name" gorm:"not null"`
+	Active            bool      `json:"active" gorm:"default:true"`
+	LeaveType         LeaveType `json:"leave_type" gorm:"type:varchar(20)"`
+	MaxBusinessDays   int       `json:"max_business_days" gorm:"not null;default:0"`
+	MinDaysInAdvance  int       `json:"min_days_in_advance" gorm:"not null;default:0"`
+	RequireNoConflict bool      `json:"require_no_conflict" gorm:"default:true"`
+	RevokeWindowHours int       `json:"revoke_window_hours" gorm:"not null;default:48"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func (AutoApprovalRule) TableName() string {
+	return "auto_approval_rules"
+}
+
+func (r *AutoApprovalRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Matches checks the request-only criteria of the rule; team conflicts are checked separately
+func (r *AutoApprovalRule) Matches(request *VacationRequest, now time.Time) bool {
+	if !r.Active {
+		return false
+	}
+	if r.LeaveType != "" && r.LeaveType != request.LeaveType {
+		return false
+	}
+	if r.MaxBusinessDays > 0 && request.BusinessDays > r.MaxBusinessDays {
+		return false
+	}
+	return r.MinDaysInAdvance <= 0 || !request.StartDate.Before(now.AddDate(0, 0, r.MinDaysInAdvance))
+}
+
+type AutoApprovalRuleRequest struct {
+	Name              string    `json:"name" binding:"required"`
+	Active            *bool     `json:"active,omitempty"`
+	LeaveType         LeaveType `json:"leave_type" binding:"omitempty,oneof=vacation unpaid compensatory"`
+	MaxBusinessDays   int       `json:"max_business_days" binding:"min=0"`
+	MinDaysInAdvance  int       `json:"min_days_in_advance" binding:"min=0"`
+	RequireNoConflict *bool     `json:"require_no_conflict,omitempty"`
+	RevokeWindowHours *int      `json:"revoke_window_hours,omitempty" binding:"omitempty,min=0"`
+}
+
+type RevokeAutoApprovalRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ChatProviderSlack = "slack"
+
+// ChatIdentity links a chat account to the user with the same email, so the
+// email is only looked up the first time the account is seen
+type ChatIdentity struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Provider    string    `json:"provider" gorm:"type:varchar(20);not null;uniqueIndex:idx_chat_identity"`
+	WorkspaceID string    `json:"workspace_id" gorm:"type:varchar(50);not null;uniqueIndex:idx_chat_identity"`
+	ExternalID  string    `json:"external_id" gorm:"type:varchar(50);not null;uniqueIndex:idx_chat_identity"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User        User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Email       string    `json:"email" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (ChatIdentity) TableName() string {
+	return "chat_identities"
+}
+
+func (i *ChatIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// SlackMessage is a reply to a slash command or a message sent to a
+// response URL
+type SlackMessage struct {
+	ResponseType    string       `json:"response_type,omitempty"`
+	ReplaceOriginal bool         `json:"replace_original,omitempty"`
+	Text            string       `json:"text"`
+	Blocks          []SlackBlock `json:"blocks,omitempty"`
+}
+
+type SlackBlock struct {
+	Type     string         `json:"type"`
+	BlockID  string         `json:"block_id,omitempty"`
+	Text     *SlackText     `json:"text,omitempty"`
+	Elements []SlackElement `json:"elements,omitempty"`
+	// Label and Element make up an input block of a modal
+	Label   *SlackText    `json:"label,omitempty"`
+	Element *SlackElement `json:"element,omitempty"`
+}
+
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SlackElement is a button of an actions block or the text field of an
+// input block
+type SlackElement struct {
+	Type      string     `json:"type"`
+	Text      *SlackText `json:"text,omitempty"`
+	ActionID  string     `json:"action_id,omitempty"`
+	Value     string     `json:"value,omitempty"`
+	Style     string     `json:"style,omitempty"`
+	Multiline bool       `json:"multiline,omitempty"`
+}
+
+// SlackView is a modal opened from a button click
+type SlackView struct {
+	Type            string       `json:"type"`
+	CallbackID      string       `json:"callback_id"`
+	PrivateMetadata string       `json:"private_metadata,omitempty"`
+	Title           *SlackText   `json:"title"`
+	Submit          *SlackText   `json:"submit,omitempty"`
+	Close           *SlackText   `json:"close,omitempty"`
+	Blocks          []SlackBlock `json:"blocks"`
+}
+
+// SlackInteraction is the payload Slack posts when a message button is
+// clicked (block_actions) or a modal is submitted (view_submission)
+type SlackInteraction struct {
+	Type string `json:"type"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	TriggerID   string `json:"trigger_id"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	View struct {
+		CallbackID      string `json:"callback_id"`
+		PrivateMetadata string `json:"private_metadata"`
+		State           struct {
+			// Values are keyed by block ID, then by action ID
+			Values map[string]map[string]struct {
+				Value string `json:"value"`
+			} `json:"values"`
+		} `json:"state"`
+	} `json:"view"`
+}
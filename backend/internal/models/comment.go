@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RequestComment is a message in the discussion thread of a vacation request
+type RequestComment struct {
+	ID                uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	VacationRequestID uuid.UUID        `json:"vacation_request_id" gorm:"type:uuid;not null;index"`
+	AuthorID          uuid.UUID        `json:"author_id" gorm:"type:uuid;not null"`
+	Author            User             `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
+	Body              string           `json:"body" gorm:"type:text;not null"`
+	Mentions          []CommentMention `json:"mentions,omitempty" gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+func (RequestComment) TableName() string {
+	return "request_comments"
+}
+
+func (rc *RequestComment) BeforeCreate(tx *gorm.DB) error {
+	if rc.ID == uuid.Nil {
+		rc.ID = uuid.New()
+	}
+	return nil
+}
+
+type CommentMention struct {
+	CommentID uuid.UUID `json:"comment_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	User      User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (CommentMention) TableName() string {
+	return "comment_mentions"
+}
+
+type CreateCommentRequest struct {
+	Body       string      `json:"body" binding:"required,max=5000"`
+	MentionIDs []uuid.UUID `json:"mention_ids" binding:"max=20"`
+}
+
+// CommentUser is the part of an author or mentioned colleague shown in a
+// thread, which participants outside the team can read too
+type CommentUser struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Department string `json:"department"`
+}
+
+func newCommentUser(user *User) *CommentUser {
+	return &CommentUser{
+		ID:         user.ID.String(),
+		Name:       user.Name,
+		Department: user.Department,
+	}
+}
+
+type CommentResponse struct {
+	ID                string         `json:"id"`
+	VacationRequestID string         `json:"vacation_request_id"`
+	AuthorID          string         `json:"author_id"`
+	Author            *CommentUser   `json:"author,omitempty"`
+	Body              string         `json:"body"`
+	Mentions          []*CommentUser `json:"mentions"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
+
+func (rc *RequestComment) ToResponse() *CommentResponse {
+	response := &CommentResponse{
+		ID:                rc.ID.String(),
+		VacationRequestID: rc.VacationRequestID.String(),
+		AuthorID:          rc.AuthorID.String(),
+		Body:              rc.Body,
+		Mentions:          []*CommentUser{},
+		CreatedAt:         rc.CreatedAt,
+	}
+
+	if rc.Author.ID != uuid.Nil {
+		response.Author = newCommentUser(&rc.Author)
+	}
+
+	for i := range rc.Mentions {
+		if rc.Mentions[i].User.ID != uuid.Nil {
+			response.Mentions = append(response.Mentions, newCommentUser(&rc.Mentions[i].User))
+		}
+	}
+
+	return response
+}
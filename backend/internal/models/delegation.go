@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApprovalDelegation lets a delegate decide on the delegator's approval steps
+// during a date range
+type ApprovalDelegation struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	DelegatorID uuid.UUID  `json:"delegator_id" gorm:"type:uuid;not null;index"`
+	Delegator   User       `json:"delegator,omitempty" gorm:"foreignKey:DelegatorID"`
+	DelegateID  uuid.UUID  `json:"delegate_id" gorm:"type:uuid;not null;index"`
+	Delegate    User       `json:"delegate,omitempty" gorm:"foreignKey:DelegateID"`
+	StartDate   time.Time  `json:"start_date" gorm:"not null"`
+	EndDate     time.Time  `json:"end_date" gorm:"not null"`
+	Reason      string     `json:"reason"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (ApprovalDelegation) TableName() string {
+	return "approval_delegations"
+}
+
+func (d *ApprovalDelegation) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+type CreateDelegationRequest struct {
+	DelegateID uuid.UUID `json:"delegate_id" binding:"required"`
+	StartDate  time.Time `json:"start_date" binding:"required"`
+	EndDate    time.Time `json:"end_date" binding:"required"`
+	Reason     string    `json:"reason"`
+}
+
+type DelegationResponse struct {
+	ID          string        `json:"id"`
+	DelegatorID string        `json:"delegator_id"`
+	Delegator   *UserResponse `json:"delegator,omitempty"`
+	DelegateID  string        `json:"delegate_id"`
+	Delegate    *UserResponse `json:"delegate,omitempty"`
+	StartDate   time.Time     `json:"start_date"`
+	EndDate     time.Time     `json:"end_date"`
+	Reason      string        `json:"reason"`
+	RevokedAt   *time.Time    `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+func (d *ApprovalDelegation) ToResponse() *DelegationResponse {
+	response := &DelegationResponse{
+		ID:          d.ID.String(),
+		DelegatorID: d.DelegatorID.String(),
+		DelegateID:  d.DelegateID.String(),
+		StartDate:   d.StartDate,
+		EndDate:     d.EndDate,
+		Reason:      d.Reason,
+		RevokedAt:   d.RevokedAt,
+		CreatedAt:   d.CreatedAt,
+	}
+
+	if d.Delegator.ID != uuid.Nil {
+		response.Delegator = d.Delegator.ToResponse()
+	}
+	if d.Delegate.ID != uuid.Nil {
+		response.Delegate = d.Delegate.ToResponse()
+	}
+
+	return response
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type FeedItemKind string
+
+const (
+	FeedLeaveApproved    FeedItemKind = "leave_approved"
+	FeedApprovalRevoked  FeedItemKind = "approval_revoked"
+	FeedLeaveStarted     FeedItemKind = "leave_started"
+	FeedLeaveCompleted   FeedItemKind = "leave_completed"
+	FeedLeaveInterrupted FeedItemKind = "leave_interrupted"
+	FeedUpcomingLeave    FeedItemKind = "upcoming_leave"
+	FeedUpcomingReturn   FeedItemKind = "upcoming_return"
+	FeedHoliday          FeedItemKind = "holiday"
+)
+
+// FeedHistoryKinds maps the history actions shown in the team feed to their
+// kind. Pending, rejected and cancelled requests stay private.
+var FeedHistoryKinds = map[HistoryAction]FeedItemKind{
+	HistoryApproved:     FeedLeaveApproved,
+	HistoryAutoApproved: FeedLeaveApproved,
+	HistoryRevoked:      FeedApprovalRevoked,
+	HistoryStarted:      FeedLeaveStarted,
+	HistoryCompleted:    FeedLeaveCompleted,
+	HistoryInterrupted:  FeedLeaveInterrupted,
+}
+
+// FeedUser is the part of a colleague shown in the feed
+type FeedUser struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Department string `json:"department"`
+}
+
+// FeedItem is an entry of the team activity feed. Past events are dated when
+// they happened and upcoming ones on the day they will happen. It carries the
+// period only: reasons, emergency contacts, comments and leave types are
+// never part of the feed.
+type FeedItem struct {
+	ID        string       `json:"id"`
+	Kind      FeedItemKind `json:"kind"`
+	At        time.Time    `json:"at"`
+	User      *FeedUser    `json:"user,omitempty"`
+	StartDate *time.Time   `json:"start_date,omitempty"`
+	EndDate   *time.Time   `json:"end_date,omitempty"`
+	Message   string       `json:"message"`
+}
+
+type FeedResponse struct {
+	Items []FeedItem `json:"items"`
+	// NextCursor fetches the following page; it is empty on the last one
+	NextCursor string `json:"next_cursor,omitempty"`
+}
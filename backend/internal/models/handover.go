@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HandoverItemType string
+
+const (
+	HandoverItemTask    HandoverItemType = "task"
+	HandoverItemContact HandoverItemType = "contact"
+	HandoverItemLink    HandoverItemType = "link"
+)
+
+type Handover struct {
+	ID                uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	VacationRequestID uuid.UUID      `json:"vacation_request_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Notes             string         `json:"notes"`
+	Items             []HandoverItem `json:"items" gorm:"foreignKey:HandoverID;constraint:OnDelete:CASCADE"`
+	ReminderSentAt    *time.Time     `json:"reminder_sent_at"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+func (Handover) TableName() string {
+	return "handovers"
+}
+
+func (h *Handover) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsEmpty reports whether the requester has not written anything in the handover yet
+func (h *Handover) IsEmpty() bool {
+	return h.Notes == "" && len(h.Items) == 0
+}
+
+type HandoverItem struct {
+	ID          uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	HandoverID  uuid.UUID        `json:"handover_id" gorm:"type:uuid;not null;index"`
+	Type        HandoverItemType `json:"type" gorm:"type:varchar(20);not null;default:'task'"`
+	Title       string           `json:"title" gorm:"not null"`
+	Details     string           `json:"details"`
+	URL         string           `json:"url"`
+	OwnerID     *uuid.UUID       `json:"owner_id" gorm:"type:uuid"`
+	Owner       *User            `json:"owner,omitempty" gorm:"foreignKey:OwnerID"`
+	Position    int              `json:"position"`
+	Completed   bool             `json:"completed" gorm:"default:false"`
+	CompletedAt *time.Time       `json:"completed_at"`
+	CompletedBy *uuid.UUID       `json:"completed_by" gorm:"type:uuid"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+func (HandoverItem) TableName() string {
+	return "handover_items"
+}
+
+func (item *HandoverItem) BeforeCreate(tx *gorm.DB) error {
+	if item.ID == uuid.Nil {
+		item.ID = uuid.New()
+	}
+	return nil
+}
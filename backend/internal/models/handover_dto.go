@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HandoverItemInput struct {
+	ID      *uuid.UUID       `json:"id,omitempty"`
+	Type    HandoverItemType `json:"type" binding:"omitempty,oneof=task contact link"`
+	Title   string           `json:"title" binding:"required"`
+	Details string           `json:"details"`
+	URL     string           `json:"url"`
+	OwnerID *uuid.UUID       `json:"owner_id,omitempty"`
+}
+
+type UpdateHandoverRequest struct {
+	Notes string              `json:"notes"`
+	Items []HandoverItemInput `json:"items" binding:"dive"`
+}
+
+type UpdateHandoverItemRequest struct {
+	Completed bool `json:"completed"`
+}
+
+type HandoverItemResponse struct {
+	ID          string        `json:"id"`
+	Type        string        `json:"type"`
+	Title       string        `json:"title"`
+	Details     string        `json:"details"`
+	URL         string        `json:"url"`
+	OwnerID     *string       `json:"owner_id,omitempty"`
+	Owner       *UserResponse `json:"owner,omitempty"`
+	Position    int           `json:"position"`
+	Completed   bool          `json:"completed"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+	CompletedBy *string       `json:"completed_by,omitempty"`
+}
+
+type HandoverResponse struct {
+	ID                string                  `json:"id"`
+	VacationRequestID string                  `json:"vacation_request_id"`
+	Notes             string                  `json:"notes"`
+	Items             []*HandoverItemResponse `json:"items"`
+	Editable          bool                    `json:"editable"`
+	CompletedItems    int                     `json:"completed_items"`
+	TotalItems        int                     `json:"total_items"`
+	UpdatedAt         time.Time               `json:"updated_at"`
+}
+
+func (item *HandoverItem) ToResponse() *HandoverItemResponse {
+	response := &HandoverItemResponse{
+		ID:          item.ID.String(),
+		Type:        string(item.Type),
+		Title:       item.Title,
+		Details:     item.Details,
+		URL:         item.URL,
+		Position:    item.Position,
+		Completed:   item.Completed,
+		CompletedAt: item.CompletedAt,
+	}
+
+	if item.OwnerID != nil {
+		ownerIDStr := item.OwnerID.String()
+		response.OwnerID = &ownerIDStr
+		if item.Owner != nil && item.Owner.ID != uuid.Nil {
+			response.Owner = item.Owner.ToResponse()
+		}
+	}
+
+	if item.CompletedBy != nil {
+		completedByStr := item.CompletedBy.String()
+		response.CompletedBy = &completedByStr
+	}
+
+	return response
+}
+
+func (h *Handover) ToResponse(editable bool) *HandoverResponse {
+	response := &HandoverResponse{
+		ID:                h.ID.String(),
+		VacationRequestID: h.VacationRequestID.String(),
+		Notes:             h.Notes,
+		Items:             []*HandoverItemResponse{},
+		Editable:          editable,
+		TotalItems:        len(h.Items),
+		UpdatedAt:         h.UpdatedAt,
+	}
+
+	for i := range h.Items {
+		if h.Items[i].Completed {
+			response.CompletedItems++
+		}
+		response.Items = append(response.Items, h.Items[i].ToResponse())
+	}
+
+	return response
+}
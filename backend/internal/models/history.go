@@ -0,0 +1,158 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HistoryAction string
+
+const (
+	HistoryCreated            HistoryAction = "created"
+	HistorySubmitted          HistoryAction = "submitted"
+	HistoryUpdated            HistoryAction = "updated"
+	HistoryCancelled          HistoryAction = "cancelled"
+	HistoryStepApproved       HistoryAction = "step_approved"
+	HistoryApproved           HistoryAction = "approved"
+	HistoryRejected           HistoryAction = "rejected"
+	HistoryAutoApproved       HistoryAction = "auto_approved"
+	HistoryRevoked            HistoryAction = "revoked"
+	HistoryEscalated          HistoryAction = "escalated"
+	HistoryStarted            HistoryAction = "started"
+	HistoryCompleted          HistoryAction = "completed"
+	HistoryInterrupted        HistoryAction = "interrupted"
+	HistorySubstituteAccepted HistoryAction = "substitute_accepted"
+	HistorySubstituteDeclined HistoryAction = "substitute_declined"
+	HistoryCommented          HistoryAction = "commented"
+)
+
+// FieldChange is the previous and new value of a changed field
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// RequestHistory is an append-only record of something that happened to a
+// vacation request. Rows are never updated or deleted.
+type RequestHistory struct {
+	ID                uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	VacationRequestID uuid.UUID      `json:"vacation_request_id" gorm:"type:uuid;not null;index"`
+	ActorID           *uuid.UUID     `json:"actor_id" gorm:"type:uuid"`
+	Actor             *User          `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	OnBehalfOf        *uuid.UUID     `json:"on_behalf_of" gorm:"type:uuid"`
+	Action            HistoryAction  `json:"action" gorm:"type:varchar(30);not null"`
+	Source            DecisionSource `json:"source" gorm:"type:varchar(20)"`
+	FromStatus        VacationStatus `json:"from_status" gorm:"type:varchar(20)"`
+	ToStatus          VacationStatus `json:"to_status" gorm:"type:varchar(20)"`
+	Changes           string         `json:"-" gorm:"type:jsonb"`
+	Comment           string         `json:"comment" gorm:"type:text"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
+
+func (RequestHistory) TableName() string {
+	return "request_history"
+}
+
+func (h *RequestHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	if h.Changes == "" {
+		h.Changes = "{}"
+	}
+	return nil
+}
+
+// SetChanges stores the changed fields as JSON
+func (h *RequestHistory) SetChanges(changes map[string]FieldChange) error {
+	if len(changes) == 0 {
+		h.Changes = "{}"
+		return nil
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	h.Changes = string(data)
+	return nil
+}
+
+// TrackedChanges compares the user-editable and decision fields of two
+// versions of a request
+func TrackedChanges(before, after *VacationRequest) map[string]FieldChange {
+	changes := map[string]FieldChange{}
+	compare := func(field string, from, to interface{}) {
+		if fmt.Sprint(from) != fmt.Sprint(to) {
+			changes[field] = FieldChange{From: from, To: to}
+		}
+	}
+
+	compare("start_date", before.StartDate.Format("2006-01-02"), after.StartDate.Format("2006-01-02"))
+	compare("end_date", before.EndDate.Format("2006-01-02"), after.EndDate.Format("2006-01-02"))
+	compare("business_days", before.BusinessDays, after.BusinessDays)
+	compare("status", before.Status, after.Status)
+	compare("leave_type", before.LeaveType, after.LeaveType)
+	compare("reason", before.Reason, after.Reason)
+	compare("emergency_contact", before.EmergencyContact, after.EmergencyContact)
+	compare("approval_comment", before.ApprovalComment, after.ApprovalComment)
+	compare("substitute_id", uuidValue(before.SubstituteID), uuidValue(after.SubstituteID))
+	compare("substitute_status", before.SubstituteStatus, after.SubstituteStatus)
+
+	return changes
+}
+
+func uuidValue(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
+
+type RequestHistoryResponse struct {
+	ID         string                 `json:"id"`
+	ActorID    *string                `json:"actor_id,omitempty"`
+	Actor      *UserResponse          `json:"actor,omitempty"`
+	OnBehalfOf *string                `json:"on_behalf_of,omitempty"`
+	Action     string                 `json:"action"`
+	Source     string                 `json:"source,omitempty"`
+	FromStatus string                 `json:"from_status,omitempty"`
+	ToStatus   string                 `json:"to_status,omitempty"`
+	Changes    map[string]FieldChange `json:"changes,omitempty"`
+	Comment    string                 `json:"comment,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+func (h *RequestHistory) ToResponse() *RequestHistoryResponse {
+	response := &RequestHistoryResponse{
+		ID:         h.ID.String(),
+		Action:     string(h.Action),
+		Source:     string(h.Source),
+		FromStatus: string(h.FromStatus),
+		ToStatus:   string(h.ToStatus),
+		Comment:    h.Comment,
+		CreatedAt:  h.CreatedAt,
+	}
+
+	if h.ActorID != nil {
+		actorIDStr := h.ActorID.String()
+		response.ActorID = &actorIDStr
+		if h.Actor != nil && h.Actor.ID != uuid.Nil {
+			response.Actor = h.Actor.ToResponse()
+		}
+	}
+
+	if h.OnBehalfOf != nil {
+		onBehalfOfStr := h.OnBehalfOf.String()
+		response.OnBehalfOf = &onBehalfOfStr
+	}
+
+	if h.Changes != "" && h.Changes != "{}" {
+		_ = json.Unmarshal([]byte(h.Changes), &response.Changes)
+	}
+
+	return response
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Holiday is a company-wide day off, announced in the team feed
+type Holiday struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Date      time.Time `json:"date" gorm:"type:date;not null;uniqueIndex"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Holiday) TableName() string {
+	return "holidays"
+}
+
+func (h *Holiday) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
+type CreateHolidayRequest struct {
+	Date time.Time `json:"date" binding:"required"`
+	Name string    `json:"name" binding:"required,max=100"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,19 +16,73 @@ const (
 	NotificationRejection NotificationType = "rejection"
 	NotificationReminder NotificationType = "reminder"
 	NotificationSystem   NotificationType = "system"
+	NotificationSubstitute NotificationType = "substitute"
+	NotificationComment    NotificationType = "comment"
 )
 
+// NotificationTypes lists every notification type
+var NotificationTypes = []NotificationType{
+	NotificationRequest,
+	NotificationApproval,
+	NotificationRejection,
+	NotificationReminder,
+	NotificationSystem,
+	NotificationSubstitute,
+	NotificationComment,
+}
+
+// Urgent reports whether the type needs someone to act or tells them of a
+// decision. Other types may wait for the user's digest.
+func (t NotificationType) Urgent() bool {
+	switch t {
+	case NotificationRequest, NotificationApproval, NotificationRejection, NotificationSubstitute:
+		return true
+	}
+	return false
+}
+
+// Email templates of request lifecycle events. Notifications without one are
+// emailed with their title and message.
+const (
+	TemplateRequestSubmitted = "request_submitted"
+	TemplateRequestApproved  = "request_approved"
+	TemplateRequestRejected  = "request_rejected"
+	TemplateRequestCancelled = "request_cancelled"
+	TemplateApprovalReminder = "approval_reminder"
+	TemplateApprovalExpired  = "approval_expired"
+	// TemplateDigest groups the notifications held for a digest
+	TemplateDigest = "digest"
+)
+
+// EmailTemplates lists every email template
+var EmailTemplates = []string{
+	TemplateRequestSubmitted,
+	TemplateRequestApproved,
+	TemplateRequestRejected,
+	TemplateRequestCancelled,
+	TemplateApprovalReminder,
+	TemplateApprovalExpired,
+	TemplateDigest,
+}
+
+// Notification is a message shown in the notification center and delivered
+// on the other channels. VacationRequestID, Template and Data let channels
+// render it with the request it is about.
 type Notification struct {
-	ID        uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID    uuid.UUID        `json:"user_id" gorm:"type:uuid;not null"`
-	User      User             `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	Type      NotificationType `json:"type" gorm:"type:varchar(20);not null"`
-	Title     string           `json:"title" gorm:"not null"`
-	Message   string           `json:"message" gorm:"not null"`
-	Read      bool             `json:"read" gorm:"default:false"`
-	ReadAt    *time.Time       `json:"read_at"`
-	CreatedAt time.Time        `json:"created_at"`
-	UpdatedAt time.Time        `json:"updated_at"`
+	ID                uuid.UUID              `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID            uuid.UUID              `json:"user_id" gorm:"type:uuid;not null;index"`
+	User              User                   `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Type              NotificationType       `json:"type" gorm:"type:varchar(20);not null"`
+	Title             string                 `json:"title" gorm:"not null"`
+	Message           string                 `json:"message" gorm:"not null"`
+	VacationRequestID *uuid.UUID             `json:"vacation_request_id" gorm:"type:uuid;index"`
+	Template          string                 `json:"template" gorm:"type:varchar(40)"`
+	Data              string                 `json:"-" gorm:"type:jsonb"`
+	Read              bool                   `json:"read" gorm:"default:false"`
+	ReadAt            *time.Time             `json:"read_at"`
+	Deliveries        []NotificationDelivery `json:"deliveries,omitempty" gorm:"foreignKey:NotificationID"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
 }
 
 func (Notification) TableName() string {
@@ -38,5 +93,75 @@ func (n *Notification) BeforeCreate(tx *gorm.DB) error {
 	if n.ID == uuid.Nil {
 		n.ID = uuid.New()
 	}
+	if n.Data == "" {
+		n.Data = "{}"
+	}
+	return nil
+}
+
+// SetData stores extra values the template needs, such as decision links
+func (n *Notification) SetData(data map[string]string) error {
+	if len(data) == 0 {
+		n.Data = "{}"
+		return nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	n.Data = string(encoded)
 	return nil
+}
+
+// TemplateData returns the values stored with SetData
+func (n *Notification) TemplateData() map[string]string {
+	data := map[string]string{}
+	if n.Data != "" {
+		_ = json.Unmarshal([]byte(n.Data), &data)
+	}
+	return data
+}
+
+type NotificationResponse struct {
+	ID                string                          `json:"id"`
+	VacationRequestID *string                         `json:"vacation_request_id,omitempty"`
+	Type              string                          `json:"type"`
+	Title             string                          `json:"title"`
+	Message           string                          `json:"message"`
+	Read              bool                            `json:"read"`
+	ReadAt            *time.Time                      `json:"read_at,omitempty"`
+	Deliveries        []*NotificationDeliveryResponse `json:"deliveries,omitempty"`
+	CreatedAt         time.Time                       `json:"created_at"`
+}
+
+type NotificationsListResponse struct {
+	Notifications []*NotificationResponse `json:"notifications"`
+	Total         int64                   `json:"total"`
+	UnreadCount   int64                   `json:"unread_count"`
+	Page          int                     `json:"page"`
+	PerPage       int                     `json:"per_page"`
+	TotalPages    int                     `json:"total_pages"`
+}
+
+func (n *Notification) ToResponse() *NotificationResponse {
+	response := &NotificationResponse{
+		ID:        n.ID.String(),
+		Type:      string(n.Type),
+		Title:     n.Title,
+		Message:   n.Message,
+		Read:      n.Read,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+
+	if n.VacationRequestID != nil {
+		requestIDStr := n.VacationRequestID.String()
+		response.VacationRequestID = &requestIDStr
+	}
+
+	for i := range n.Deliveries {
+		response.Deliveries = append(response.Deliveries, n.Deliveries[i].ToResponse())
+	}
+
+	return response
 }
\ No newline at end of file
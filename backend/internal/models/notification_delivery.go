@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification channels known to the dispatcher
+const (
+	ChannelInApp   = "in_app"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed"
+	// DeliveryDigest is held until the recipient's next digest
+	DeliveryDigest DeliveryStatus = "digest"
+)
+
+// NotificationDelivery tracks one notification through one channel
+type NotificationDelivery struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	NotificationID uuid.UUID      `json:"notification_id" gorm:"type:uuid;not null;uniqueIndex:idx_notification_delivery_channel"`
+	Notification   *Notification  `json:"notification,omitempty" gorm:"foreignKey:NotificationID"`
+	Channel        string         `json:"channel" gorm:"type:varchar(30);not null;uniqueIndex:idx_notification_delivery_channel"`
+	Status         DeliveryStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Attempts       int            `json:"attempts" gorm:"not null;default:0"`
+	LastError      string         `json:"last_error" gorm:"type:text"`
+	NextAttemptAt  *time.Time     `json:"next_attempt_at" gorm:"index"`
+	SentAt         *time.Time     `json:"sent_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
+
+func (d *NotificationDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// NotificationChannelPreference switches a channel on or off for one user,
+// for a single notification type or, with an empty type, for all of them
+type NotificationChannelPreference struct {
+	ID        uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_channel_preference"`
+	Type      NotificationType `json:"type" gorm:"type:varchar(20);not null;default:'';uniqueIndex:idx_channel_preference"`
+	Channel   string           `json:"channel" gorm:"type:varchar(30);not null;uniqueIndex:idx_channel_preference"`
+	Enabled   bool             `json:"enabled" gorm:"not null"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+func (NotificationChannelPreference) TableName() string {
+	return "notification_channel_preferences"
+}
+
+func (p *NotificationChannelPreference) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+type NotificationDeliveryResponse struct {
+	Channel  string     `json:"channel"`
+	Status   string     `json:"status"`
+	Attempts int        `json:"attempts"`
+	SentAt   *time.Time `json:"sent_at,omitempty"`
+}
+
+func (d *NotificationDelivery) ToResponse() *NotificationDeliveryResponse {
+	return &NotificationDeliveryResponse{
+		Channel:  d.Channel,
+		Status:   string(d.Status),
+		Attempts: d.Attempts,
+		SentAt:   d.SentAt,
+	}
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DigestFrequency string
+
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// DefaultTimeZone is used for users who have not chosen one
+const DefaultTimeZone = "America/Sao_Paulo"
+
+// NotificationSettings controls when a user is reached outside the app.
+// Quiet hours are "15:04" times in the user's time zone; deliveries falling
+// inside them wait until they end. With a digest, non-urgent emails are held
+// and sent together at DigestHour (on DigestWeekday for weekly digests).
+type NotificationSettings struct {
+	ID              uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID          uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	TimeZone        string          `json:"time_zone" gorm:"type:varchar(60);not null;default:'America/Sao_Paulo'"`
+	QuietHoursStart string          `json:"quiet_hours_start" gorm:"type:varchar(5)"`
+	QuietHoursEnd   string          `json:"quiet_hours_end" gorm:"type:varchar(5)"`
+	Digest          DigestFrequency `json:"digest" gorm:"type:varchar(10);not null;default:'off'"`
+	DigestHour      int             `json:"digest_hour" gorm:"not null;default:8"`
+	DigestWeekday   time.Weekday    `json:"digest_weekday" gorm:"not null;default:1"`
+	LastDigestAt    *time.Time      `json:"last_digest_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+func (NotificationSettings) TableName() string {
+	return "notification_settings"
+}
+
+func (s *NotificationSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// DefaultNotificationSettings are the settings of users who never changed them
+func DefaultNotificationSettings(userID uuid.UUID) NotificationSettings {
+	return NotificationSettings{
+		UserID:        userID,
+		TimeZone:      DefaultTimeZone,
+		Digest:        DigestOff,
+		DigestHour:    8,
+		DigestWeekday: time.Monday,
+	}
+}
+
+// Location returns the user's time zone, or the default one when it is unknown
+func (s *NotificationSettings) Location() *time.Location {
+	if location, err := time.LoadLocation(s.TimeZone); err == nil {
+		return location
+	}
+	location, err := time.LoadLocation(DefaultTimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// QuietUntil returns when the quiet hours around t end, or nil when t is
+// outside them
+func (s *NotificationSettings) QuietUntil(t time.Time) *time.Time {
+	start, err := time.Parse("15:04", s.QuietHoursStart)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("15:04", s.QuietHoursEnd)
+	if err != nil {
+		return nil
+	}
+
+	local := t.In(s.Location())
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+	endToday := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, local.Location())
+
+	switch {
+	case startMinute == endMinute:
+		return nil
+	case startMinute < endMinute:
+		if minute >= startMinute && minute < endMinute {
+			return &endToday
+		}
+	default:
+		// Quiet hours crossing midnight, such as 22:00 to 07:00
+		if minute >= startMinute {
+			endTomorrow := endToday.AddDate(0, 0, 1)
+			return &endTomorrow
+		}
+		if minute < endMinute {
+			return &endToday
+		}
+	}
+	return nil
+}
+
+// DigestDue reports whether a digest was scheduled since the last one was sent
+func (s *NotificationSettings) DigestDue(now time.Time) bool {
+	if s.Digest != DigestDaily && s.Digest != DigestWeekly {
+		return false
+	}
+
+	local := now.In(s.Location())
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), s.DigestHour, 0, 0, 0, local.Location())
+	for scheduled.After(local) || (s.Digest == DigestWeekly && scheduled.Weekday() != s.DigestWeekday) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+	return s.LastDigestAt == nil || s.LastDigestAt.Before(scheduled)
+}
+
+type ChannelPreferenceInput struct {
+	Type    NotificationType `json:"type"`
+	Channel string           `json:"channel" binding:"required"`
+	Enabled *bool            `json:"enabled" binding:"required"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Locale          *string          `json:"locale"`
+	TimeZone        *string          `json:"time_zone"`
+	QuietHoursStart *string          `json:"quiet_hours_start"`
+	QuietHoursEnd   *string          `json:"quiet_hours_end"`
+	Digest          *DigestFrequency `json:"digest" binding:"omitempty,oneof=off daily weekly"`
+	DigestHour      *int             `json:"digest_hour" binding:"omitempty,min=0,max=23"`
+	DigestWeekday   *int             `json:"digest_weekday" binding:"omitempty,min=0,max=6"`
+	// Channels replaces every channel preference of the user when present
+	Channels []ChannelPreferenceInput `json:"channels" binding:"omitempty,dive"`
+}
+
+type ChannelPreferenceResponse struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+}
+
+type NotificationPreferencesResponse struct {
+	Locale          string                       `json:"locale"`
+	TimeZone        string                       `json:"time_zone"`
+	QuietHoursStart string                       `json:"quiet_hours_start"`
+	QuietHoursEnd   string                       `json:"quiet_hours_end"`
+	Digest          string                       `json:"digest"`
+	DigestHour      int                          `json:"digest_hour"`
+	DigestWeekday   int                          `json:"digest_weekday"`
+	LastDigestAt    *time.Time                   `json:"last_digest_at,omitempty"`
+	Channels        []*ChannelPreferenceResponse `json:"channels"`
+}
+
+func (s *NotificationSettings) ToResponse(locale string, preferences []NotificationChannelPreference) *NotificationPreferencesResponse {
+	response := &NotificationPreferencesResponse{
+		Locale:          locale,
+		TimeZone:        s.TimeZone,
+		QuietHoursStart: s.QuietHoursStart,
+		QuietHoursEnd:   s.QuietHoursEnd,
+		Digest:          string(s.Digest),
+		DigestHour:      s.DigestHour,
+		DigestWeekday:   int(s.DigestWeekday),
+		LastDigestAt:    s.LastDigestAt,
+		Channels:        make([]*ChannelPreferenceResponse, 0, len(preferences)),
+	}
+	for _, preference := range preferences {
+		response.Channels = append(response.Channels, &ChannelPreferenceResponse{
+			Type:    string(preference.Type),
+			Channel: preference.Channel,
+			Enabled: preference.Enabled,
+		})
+	}
+	return response
+}
@@ -0,0 +1,18 @@
+package models
+
+// OrgChartNode is a person in the org chart together with the people who
+// report to them. Counters cover the whole subtree below the person.
+type OrgChartNode struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Email         string          `json:"email"`
+	Role          string          `json:"role"`
+	Department    string          `json:"department"`
+	ManagerID     *string         `json:"manager_id,omitempty"`
+	OnLeave       bool            `json:"on_leave"`
+	ReturnDate    *string         `json:"return_date,omitempty"`
+	DirectReports int             `json:"direct_reports"`
+	Headcount     int             `json:"headcount"`
+	OnLeaveCount  int             `json:"on_leave_count"`
+	Reports       []*OrgChartNode `json:"reports"`
+}
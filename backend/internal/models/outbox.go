@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxPublished OutboxStatus = "published"
+	OutboxFailed    OutboxStatus = "failed"
+)
+
+// Aggregates domain events are about
+const (
+	AggregateVacationRequest = "vacation_request"
+	AggregateUser            = "user"
+	AggregateDelegation      = "delegation"
+)
+
+const (
+	// EventUserUpdated is the outbox event of a change to a user's data
+	EventUserUpdated = "user.updated"
+	// EventDelegationCreated is the outbox event of a new approval delegation
+	EventDelegationCreated = "delegation.created"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change
+// it describes. The relay hands it to every consumer and marks it published
+// once all of them processed it.
+type OutboxEvent struct {
+	ID            int64        `json:"id" gorm:"primaryKey;autoIncrement"`
+	Aggregate     string       `json:"aggregate" gorm:"type:varchar(30);not null"`
+	AggregateID   uuid.UUID    `json:"aggregate_id" gorm:"type:uuid;not null;index"`
+	Type          string       `json:"type" gorm:"type:varchar(50);not null"`
+	Payload       string       `json:"payload" gorm:"type:jsonb;not null"`
+	Status        OutboxStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_event_due,priority:1"`
+	Attempts      int          `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time    `json:"next_attempt_at" gorm:"not null;index:idx_outbox_event_due,priority:2"`
+	LastError     string       `json:"last_error" gorm:"type:text"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"index"`
+	PublishedAt   *time.Time   `json:"published_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// OutboxConsumption records that a consumer processed an event. It is written
+// in the consumer's own transaction, so each consumer applies an event once.
+type OutboxConsumption struct {
+	EventID     int64     `json:"event_id" gorm:"primaryKey;autoIncrement:false"`
+	Consumer    string    `json:"consumer" gorm:"primaryKey;type:varchar(30)"`
+	ProcessedAt time.Time `json:"processed_at" gorm:"not null;index"`
+	// Lag is how long after the event was written it was processed
+	LagMs int64 `json:"lag_ms" gorm:"not null"`
+}
+
+func (OutboxConsumption) TableName() string {
+	return "outbox_consumptions"
+}
+
+// RequestEventPayload is the payload of vacation request events, which
+// mirror the request history
+type RequestEventPayload struct {
+	HistoryID string `json:"history_id"`
+	// CommentID is set on the events of new comments
+	CommentID string `json:"comment_id,omitempty"`
+}
+
+// RequestEventType names the outbox event of a history action
+func RequestEventType(action HistoryAction) string {
+	return AggregateVacationRequest + "." + string(action)
+}
+
+// OutboxConsumerMetrics describes how far behind a consumer is
+type OutboxConsumerMetrics struct {
+	Name string `json:"name"`
+	// Events still waiting for this consumer
+	Pending int64 `json:"pending"`
+	// The other fields cover the last hour
+	Processed       int64      `json:"processed"`
+	AvgLagMs        float64    `json:"avg_lag_ms"`
+	MaxLagMs        int64      `json:"max_lag_ms"`
+	LastProcessedAt *time.Time `json:"last_processed_at,omitempty"`
+}
+
+type OutboxMetricsResponse struct {
+	Pending int64 `json:"pending"`
+	Failed  int64 `json:"failed"`
+	// OldestPendingAgeSeconds is the current lag of the relay
+	OldestPendingAgeSeconds float64                 `json:"oldest_pending_age_seconds"`
+	Consumers               []OutboxConsumerMetrics `json:"consumers"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReminderKind string
+
+const (
+	ReminderUpcomingLeave ReminderKind = "upcoming_leave"
+	ReminderReturn        ReminderKind = "return"
+	ReminderLeaveNotice   ReminderKind = "leave_notice"
+)
+
+// ReminderLog records the last reminder of a kind sent to someone about a
+// request, so the scheduler does not repeat it
+type ReminderLog struct {
+	ID                uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	VacationRequestID uuid.UUID    `json:"vacation_request_id" gorm:"type:uuid;not null;uniqueIndex:idx_reminder_log"`
+	Kind              ReminderKind `json:"kind" gorm:"type:varchar(30);not null;uniqueIndex:idx_reminder_log"`
+	RecipientID       uuid.UUID    `json:"recipient_id" gorm:"type:uuid;not null;uniqueIndex:idx_reminder_log"`
+	SentAt            time.Time    `json:"sent_at" gorm:"not null"`
+}
+
+func (ReminderLog) TableName() string {
+	return "reminder_logs"
+}
+
+func (l *ReminderLog) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
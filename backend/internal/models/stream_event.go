@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StreamEventType string
+
+const (
+	StreamNotification  StreamEventType = "notification"
+	StreamRequestStatus StreamEventType = "request_status"
+)
+
+// StreamEvent is a change pushed to a user's real-time stream. IDs increase
+// so clients can resume after the last event they received.
+type StreamEvent struct {
+	ID        int64           `json:"id" gorm:"primaryKey;autoIncrement;index:idx_stream_event_user_replay,priority:2"`
+	UserID    uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;index:idx_stream_event_user_replay,priority:1"`
+	Type      StreamEventType `json:"type" gorm:"type:varchar(30);not null"`
+	Payload   string          `json:"payload" gorm:"type:jsonb;not null"`
+	CreatedAt time.Time       `json:"created_at" gorm:"index"`
+}
+
+func (StreamEvent) TableName() string {
+	return "stream_events"
+}
+
+// RequestStatusEvent is the payload of a request_status event
+type RequestStatusEvent struct {
+	VacationRequestID string `json:"vacation_request_id"`
+	Action            string `json:"action"`
+	FromStatus        string `json:"from_status,omitempty"`
+	ToStatus          string `json:"to_status"`
+}
@@ -13,6 +13,7 @@ const (
 	RoleEmployee UserRole = "employee"
 	RoleManager  UserRole = "manager"
 	RoleAdmin    UserRole = "admin"
+	RoleHR       UserRole = "hr"
 )
 
 type User struct {
@@ -26,6 +27,7 @@ type User struct {
 	VacationBalance int            `json:"vacation_balance" gorm:"default:30"`
 	Department      string         `json:"department"`
 	Active          bool           `json:"active" gorm:"default:true"`
+	Locale          string         `json:"locale" gorm:"type:varchar(10);not null;default:'pt-BR'"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
@@ -10,29 +10,61 @@ import (
 type VacationStatus string
 
 const (
-	StatusPending   VacationStatus = "pending"
-	StatusApproved  VacationStatus = "approved"
-	StatusRejected  VacationStatus = "rejected"
-	StatusCancelled VacationStatus = "cancelled"
+	StatusDraft       VacationStatus = "draft"
+	StatusPending     VacationStatus = "pending"
+	StatusApproved    VacationStatus = "approved"
+	StatusRejected    VacationStatus = "rejected"
+	StatusCancelled   VacationStatus = "cancelled"
+	StatusInProgress  VacationStatus = "in_progress"
+	StatusCompleted   VacationStatus = "completed"
+	StatusInterrupted VacationStatus = "interrupted"
+)
+
+type LeaveType string
+
+const (
+	LeaveTypeVacation     LeaveType = "vacation"
+	LeaveTypeUnpaid       LeaveType = "unpaid"
+	LeaveTypeCompensatory LeaveType = "compensatory"
+)
+
+type SubstituteStatus string
+
+const (
+	SubstitutePending  SubstituteStatus = "pending"
+	SubstituteAccepted SubstituteStatus = "accepted"
+	SubstituteDeclined SubstituteStatus = "declined"
 )
 
 type VacationRequest struct {
-	ID                uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID            uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
-	User              User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	StartDate         time.Time      `json:"start_date" gorm:"not null"`
-	EndDate           time.Time      `json:"end_date" gorm:"not null"`
-	BusinessDays      int            `json:"business_days" gorm:"not null"`
-	Status            VacationStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
-	Reason            string         `json:"reason"`
-	EmergencyContact  string         `json:"emergency_contact" gorm:"not null"`
-	ApprovedBy        *uuid.UUID     `json:"approved_by" gorm:"type:uuid"`
-	Approver          *User          `json:"approver,omitempty" gorm:"foreignKey:ApprovedBy"`
-	ApprovalDate      *time.Time     `json:"approval_date"`
-	ApprovalComment   string         `json:"approval_comment"`
-	CreatedAt         time.Time      `json:"created_at"`
-	UpdatedAt         time.Time      `json:"updated_at"`
-	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                    uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID                uuid.UUID        `json:"user_id" gorm:"type:uuid;not null"`
+	User                  User             `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	StartDate             time.Time        `json:"start_date" gorm:"not null"`
+	EndDate               time.Time        `json:"end_date" gorm:"not null"`
+	BusinessDays          int              `json:"business_days" gorm:"not null"`
+	Status                VacationStatus   `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	LeaveType             LeaveType        `json:"leave_type" gorm:"type:varchar(20);not null;default:'vacation'"`
+	Reason                string           `json:"reason"`
+	EmergencyContact      string           `json:"emergency_contact" gorm:"not null"`
+	ApprovedBy            *uuid.UUID       `json:"approved_by" gorm:"type:uuid"`
+	Approver              *User            `json:"approver,omitempty" gorm:"foreignKey:ApprovedBy"`
+	ApprovalDate          *time.Time       `json:"approval_date"`
+	ApprovalComment       string           `json:"approval_comment"`
+	ApprovedOnBehalfOf    *uuid.UUID       `json:"approved_on_behalf_of" gorm:"type:uuid"`
+	AutoApprovalRuleID    *uuid.UUID       `json:"auto_approval_rule_id" gorm:"type:uuid"`
+	RevocableUntil        *time.Time       `json:"revocable_until"`
+	SubstituteID          *uuid.UUID       `json:"substitute_id" gorm:"type:uuid;index"`
+	Substitute            *User            `json:"substitute,omitempty" gorm:"foreignKey:SubstituteID"`
+	SubstituteStatus      SubstituteStatus `json:"substitute_status" gorm:"type:varchar(20)"`
+	SubstituteComment     string           `json:"substitute_comment"`
+	SubstituteRespondedAt *time.Time       `json:"substitute_responded_at"`
+	ApprovalSteps         []ApprovalStep   `json:"approval_steps,omitempty" gorm:"foreignKey:VacationRequestID"`
+	History               []RequestHistory `json:"history,omitempty" gorm:"foreignKey:VacationRequestID"`
+	Version               int              `json:"version" gorm:"not null;default:1"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt   `json:"-" gorm:"index"`
 }
 
 func (VacationRequest) TableName() string {
@@ -45,6 +77,20 @@ func (vr *VacationRequest) BeforeCreate(tx *gorm.DB) error {
 	}
 	// Calculate business days
 	vr.BusinessDays = calculateBusinessDays(vr.StartDate, vr.EndDate)
+	if vr.Version == 0 {
+		vr.Version = 1
+	}
+	return nil
+}
+
+// BeforeUpdate bumps the version on every write so a client or transaction
+// holding an older copy can tell the request changed underneath it
+func (vr *VacationRequest) BeforeUpdate(tx *gorm.DB) error {
+	if _, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+		tx.Statement.SetColumn("version", gorm.Expr("version + 1"))
+		return nil
+	}
+	vr.Version++
 	return nil
 }
 
@@ -56,4 +102,4 @@ func calculateBusinessDays(start, end time.Time) int {
 		}
 	}
 	return days
-}
\ No newline at end of file
+}
@@ -1,16 +1,21 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type CreateVacationRequestRequest struct {
-	StartDate        time.Time `json:"start_date" binding:"required"`
-	EndDate          time.Time `json:"end_date" binding:"required"`
-	Reason           string    `json:"reason"`
-	EmergencyContact string    `json:"emergency_contact" binding:"required"`
+	StartDate        time.Time  `json:"start_date" binding:"required"`
+	EndDate          time.Time  `json:"end_date" binding:"required"`
+	Reason           string     `json:"reason"`
+	EmergencyContact string     `json:"emergency_contact" binding:"required"`
+	SubstituteID     *uuid.UUID `json:"substitute_id"`
+	LeaveType        LeaveType  `json:"leave_type" binding:"omitempty,oneof=vacation unpaid compensatory"`
+	// Draft keeps the request out of approval until it is submitted
+	Draft bool `json:"draft"`
 }
 
 type UpdateVacationRequestRequest struct {
@@ -18,28 +23,101 @@ type UpdateVacationRequestRequest struct {
 	EndDate          *time.Time `json:"end_date,omitempty"`
 	Reason           *string    `json:"reason,omitempty"`
 	EmergencyContact *string    `json:"emergency_contact,omitempty"`
+	// SubstituteID sent as null removes the substitute
+	SubstituteID NullableUUID `json:"substitute_id"`
+	// Version, when sent, must match the stored request
+	Version *int `json:"version,omitempty"`
+}
+
+// NullableUUID tells a field left out of a JSON body, with Set false, apart
+// from one sent as null, with Set true and no Value
+type NullableUUID struct {
+	Set   bool
+	Value *uuid.UUID
+}
+
+func (n *NullableUUID) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+	var id uuid.UUID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	n.Value = &id
+	return nil
 }
 
 type VacationRequestResponse struct {
-	ID                string                 `json:"id"`
-	UserID            string                 `json:"user_id"`
-	User              *UserResponse          `json:"user,omitempty"`
-	StartDate         time.Time              `json:"start_date"`
-	EndDate           time.Time              `json:"end_date"`
-	BusinessDays      int                    `json:"business_days"`
-	Status            string                 `json:"status"`
-	Reason            string                 `json:"reason"`
-	EmergencyContact  string                 `json:"emergency_contact"`
-	ApprovedBy        *string                `json:"approved_by,omitempty"`
-	Approver          *UserResponse          `json:"approver,omitempty"`
-	ApprovalDate      *time.Time             `json:"approval_date,omitempty"`
-	ApprovalComment   string                 `json:"approval_comment"`
-	CreatedAt         time.Time              `json:"created_at"`
-	UpdatedAt         time.Time              `json:"updated_at"`
+	ID                    string                    `json:"id"`
+	UserID                string                    `json:"user_id"`
+	User                  *UserResponse             `json:"user,omitempty"`
+	StartDate             time.Time                 `json:"start_date"`
+	EndDate               time.Time                 `json:"end_date"`
+	BusinessDays          int                       `json:"business_days"`
+	Status                string                    `json:"status"`
+	LeaveType             string                    `json:"leave_type"`
+	Reason                string                    `json:"reason"`
+	EmergencyContact      string                    `json:"emergency_contact"`
+	ApprovedBy            *string                   `json:"approved_by,omitempty"`
+	Approver              *UserResponse             `json:"approver,omitempty"`
+	ApprovalDate          *time.Time                `json:"approval_date,omitempty"`
+	ApprovalComment       string                    `json:"approval_comment"`
+	ApprovedOnBehalfOf    *string                   `json:"approved_on_behalf_of,omitempty"`
+	AutoApproved          bool                      `json:"auto_approved"`
+	RevocableUntil        *time.Time                `json:"revocable_until,omitempty"`
+	SubstituteID          *string                   `json:"substitute_id,omitempty"`
+	Substitute            *UserResponse             `json:"substitute,omitempty"`
+	SubstituteStatus      string                    `json:"substitute_status,omitempty"`
+	SubstituteComment     string                    `json:"substitute_comment,omitempty"`
+	SubstituteRespondedAt *time.Time                `json:"substitute_responded_at,omitempty"`
+	ApprovalSteps         []*ApprovalStepResponse   `json:"approval_steps,omitempty"`
+	History               []*RequestHistoryResponse `json:"history,omitempty"`
+	Version               int                       `json:"version"`
+	CreatedAt             time.Time                 `json:"created_at"`
+	UpdatedAt             time.Time                 `json:"updated_at"`
+}
+
+type InterruptVacationRequest struct {
+	ReturnDate time.Time `json:"return_date" binding:"required"`
+	Comment    string    `json:"comment" binding:"required"`
 }
 
 type ApprovalRequest struct {
 	Comment string `json:"comment"`
+	// Version, when sent, must match the request the approver looked at
+	Version *int `json:"version"`
+}
+
+type BulkDecisionItem struct {
+	ID      uuid.UUID `json:"id" binding:"required"`
+	Comment string    `json:"comment"`
+	Version *int      `json:"version"`
+}
+
+type BulkDecisionRequest struct {
+	Decision string             `json:"decision" binding:"required,oneof=approve reject"`
+	Comment  string             `json:"comment"`
+	Items    []BulkDecisionItem `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+type BulkDecisionResult struct {
+	ID      string                   `json:"id"`
+	Status  string                   `json:"status"`
+	Error   string                   `json:"error,omitempty"`
+	Request *VacationRequestResponse `json:"request,omitempty"`
+}
+
+type BulkDecisionResponse struct {
+	Results   []*BulkDecisionResult `json:"results"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+}
+
+type SubstituteResponseRequest struct {
+	Comment string `json:"comment"`
 }
 
 type VacationRequestsListResponse struct {
@@ -58,9 +136,13 @@ func (vr *VacationRequest) ToResponse() *VacationRequestResponse {
 		EndDate:          vr.EndDate,
 		BusinessDays:     vr.BusinessDays,
 		Status:           string(vr.Status),
+		LeaveType:        string(vr.LeaveType),
 		Reason:           vr.Reason,
 		EmergencyContact: vr.EmergencyContact,
 		ApprovalComment:  vr.ApprovalComment,
+		AutoApproved:     vr.AutoApprovalRuleID != nil,
+		RevocableUntil:   vr.RevocableUntil,
+		Version:          vr.Version,
 		CreatedAt:        vr.CreatedAt,
 		UpdatedAt:        vr.UpdatedAt,
 	}
@@ -77,9 +159,33 @@ func (vr *VacationRequest) ToResponse() *VacationRequestResponse {
 		}
 	}
 
+	if vr.ApprovedOnBehalfOf != nil {
+		onBehalfOfStr := vr.ApprovedOnBehalfOf.String()
+		response.ApprovedOnBehalfOf = &onBehalfOfStr
+	}
+
 	if vr.ApprovalDate != nil {
 		response.ApprovalDate = vr.ApprovalDate
 	}
 
+	if vr.SubstituteID != nil {
+		substituteIDStr := vr.SubstituteID.String()
+		response.SubstituteID = &substituteIDStr
+		response.SubstituteStatus = string(vr.SubstituteStatus)
+		response.SubstituteComment = vr.SubstituteComment
+		response.SubstituteRespondedAt = vr.SubstituteRespondedAt
+		if vr.Substitute != nil && vr.Substitute.ID != uuid.Nil {
+			response.Substitute = vr.Substitute.ToResponse()
+		}
+	}
+
+	for i := range vr.ApprovalSteps {
+		response.ApprovalSteps = append(response.ApprovalSteps, vr.ApprovalSteps[i].ToResponse())
+	}
+
+	for i := range vr.History {
+		response.History = append(response.History, vr.History[i].ToResponse())
+	}
+
 	return response
-}
\ No newline at end of file
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// VacationEvent is something that moves a vacation request between statuses
+type VacationEvent string
+
+const (
+	EventSubmit    VacationEvent = "submit"
+	EventApprove   VacationEvent = "approve"
+	EventReject    VacationEvent = "reject"
+	EventCancel    VacationEvent = "cancel"
+	EventRevoke    VacationEvent = "revoke"
+	EventStart     VacationEvent = "start"
+	EventComplete  VacationEvent = "complete"
+	EventInterrupt VacationEvent = "interrupt"
+)
+
+var (
+	// ReservedStatuses hold the period: a new request may not overlap them
+	ReservedStatuses = []VacationStatus{StatusPending, StatusApproved, StatusInProgress}
+	// OnLeaveStatuses are granted leave that has not finished yet
+	OnLeaveStatuses = []VacationStatus{StatusApproved, StatusInProgress}
+	// GrantedStatuses are every approved leave, upcoming, running or finished
+	GrantedStatuses = []VacationStatus{StatusApproved, StatusInProgress, StatusCompleted, StatusInterrupted}
+)
+
+// OverlapConstraint is the database constraint keeping the reserved requests
+// of an employee from sharing a day
+const OverlapConstraint = "vacation_requests_no_overlap"
+
+// TransitionError explains why an event cannot be applied to a request
+type TransitionError struct {
+	Event  VacationEvent
+	Status VacationStatus
+	Reason string
+}
+
+func (e *TransitionError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("cannot %s vacation request in status %s: %s", e.Event, e.Status, e.Reason)
+	}
+	return fmt.Sprintf("cannot %s vacation request in status %s", e.Event, e.Status)
+}
+
+// TransitionEffect describes what an applied transition changed and what the
+// caller still has to persist besides the request itself
+type TransitionEffect struct {
+	Event VacationEvent
+	From  VacationStatus
+	To    VacationStatus
+	// BalanceDelta is added to the requester's vacation balance
+	BalanceDelta int
+}
+
+type vacationTransition struct {
+	from  []VacationStatus
+	to    VacationStatus
+	guard func(vr *VacationRequest, at time.Time) string
+}
+
+var vacationTransitions = map[VacationEvent]vacationTransition{
+	EventSubmit: {
+		from: []VacationStatus{StatusDraft},
+		to:   StatusPending,
+	},
+	EventApprove: {
+		from: []VacationStatus{StatusPending},
+		to:   StatusApproved,
+	},
+	EventReject: {
+		from: []VacationStatus{StatusPending},
+		to:   StatusRejected,
+	},
+	EventCancel: {
+		from: []VacationStatus{StatusDraft, StatusPending},
+		to:   StatusCancelled,
+	},
+	EventRevoke: {
+		from: []VacationStatus{StatusApproved},
+		to:   StatusRejected,
+	},
+	EventStart: {
+		from: []VacationStatus{StatusApproved},
+		to:   StatusInProgress,
+		guard: func(vr *VacationRequest, at time.Time) string {
+			if at.Before(vr.StartDate) {
+				return "the leave has not started yet"
+			}
+			return ""
+		},
+	},
+	EventComplete: {
+		from: []VacationStatus{StatusInProgress},
+		to:   StatusCompleted,
+		guard: func(vr *VacationRequest, at time.Time) string {
+			if !at.After(lastDayOf(vr.EndDate)) {
+				return "the leave has not ended yet"
+			}
+			return ""
+		},
+	},
+	EventInterrupt: {
+		from: []VacationStatus{StatusInProgress},
+		to:   StatusInterrupted,
+	},
+}
+
+// Can reports whether the event may be applied to the request at the given
+// time. The returned error is a *TransitionError.
+func (vr *VacationRequest) Can(event VacationEvent, at time.Time) error {
+	transition, ok := vacationTransitions[event]
+	if !ok {
+		return &TransitionError{Event: event, Status: vr.Status, Reason: "unknown event"}
+	}
+
+	allowed := false
+	for _, status := range transition.from {
+		if vr.Status == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return &TransitionError{Event: event, Status: vr.Status}
+	}
+
+	if transition.guard != nil {
+		if reason := transition.guard(vr, at); reason != "" {
+			return &TransitionError{Event: event, Status: vr.Status, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// Apply moves the request to the event's target status and updates the
+// fields the transition owns. Interruptions go through Interrupt instead.
+func (vr *VacationRequest) Apply(event VacationEvent, at time.Time) (*TransitionEffect, error) {
+	if event == EventInterrupt {
+		return nil, &TransitionError{Event: event, Status: vr.Status, Reason: "a return date is required"}
+	}
+	if err := vr.Can(event, at); err != nil {
+		return nil, err
+	}
+
+	effect := &TransitionEffect{Event: event, From: vr.Status, To: vacationTransitions[event].to}
+	switch event {
+	case EventApprove:
+		if vr.UsesBalance() {
+			effect.BalanceDelta = -vr.BusinessDays
+		}
+	case EventRevoke:
+		if vr.UsesBalance() {
+			effect.BalanceDelta = vr.BusinessDays
+		}
+		vr.RevocableUntil = nil
+	}
+
+	vr.Status = effect.To
+	return effect, nil
+}
+
+// Interrupt ends a running leave early. The requester is back at work on the
+// return date and the business days not taken go back to the balance.
+func (vr *VacationRequest) Interrupt(returnDate, at time.Time) (*TransitionEffect, error) {
+	if err := vr.Can(EventInterrupt, at); err != nil {
+		return nil, err
+	}
+	if !returnDate.After(vr.StartDate) || returnDate.After(vr.EndDate) {
+		return nil, &TransitionError{Event: EventInterrupt, Status: vr.Status,
+			Reason: "the return date must be after the start date and within the leave"}
+	}
+
+	effect := &TransitionEffect{Event: EventInterrupt, From: vr.Status, To: StatusInterrupted}
+
+	taken := calculateBusinessDays(vr.StartDate, returnDate.AddDate(0, 0, -1))
+	if vr.UsesBalance() {
+		effect.BalanceDelta = vr.BusinessDays - taken
+	}
+
+	vr.EndDate = returnDate.AddDate(0, 0, -1)
+	vr.BusinessDays = taken
+	vr.Status = StatusInterrupted
+	return effect, nil
+}
+
+// UsesBalance reports whether the leave is taken from the vacation balance;
+// unpaid and compensatory leave are not
+func (vr *VacationRequest) UsesBalance() bool {
+	return vr.LeaveType == LeaveTypeVacation
+}
+
+// Editable reports whether the requester may still change the request
+func (vr *VacationRequest) Editable() bool {
+	return vr.Status == StatusDraft || vr.Status == StatusPending
+}
+
+// lastDayOf returns the last instant of the day of t
+func lastDayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
@@ -0,0 +1,238 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Events sent to webhook subscriptions
+const (
+	WebhookRequestCreated   = "vacation_request.created"
+	WebhookRequestApproved  = "vacation_request.approved"
+	WebhookRequestRejected  = "vacation_request.rejected"
+	WebhookRequestCancelled = "vacation_request.cancelled"
+	WebhookUserUpdated      = "user.updated"
+)
+
+// WebhookEvents lists every event a subscription may choose
+var WebhookEvents = []string{
+	WebhookRequestCreated,
+	WebhookRequestApproved,
+	WebhookRequestRejected,
+	WebhookRequestCancelled,
+	WebhookUserUpdated,
+}
+
+// WebhookSubscription is an external endpoint receiving the chosen events.
+// Payloads are signed with Secret so the receiver can verify them.
+type WebhookSubscription struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	URL         string     `json:"url" gorm:"not null"`
+	Description string     `json:"description"`
+	Events      string     `json:"-" gorm:"type:jsonb;not null"`
+	Secret      string     `json:"-" gorm:"not null"`
+	Active      bool       `json:"active" gorm:"not null;default:true"`
+	CreatedBy   *uuid.UUID `json:"created_by" gorm:"type:uuid"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+func (s *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.Events == "" {
+		s.Events = "[]"
+	}
+	return nil
+}
+
+// EventList returns the events the subscription receives
+func (s *WebhookSubscription) EventList() []string {
+	events := []string{}
+	_ = json.Unmarshal([]byte(s.Events), &events)
+	return events
+}
+
+func (s *WebhookSubscription) SetEvents(events []string) error {
+	encoded, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	s.Events = string(encoded)
+	return nil
+}
+
+// WebhookDelivery is one attempt series of posting an event to a
+// subscription. A redelivery is a new delivery of the same event.
+type WebhookDelivery struct {
+	ID             uuid.UUID            `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	SubscriptionID uuid.UUID            `json:"subscription_id" gorm:"type:uuid;not null;index"`
+	Subscription   *WebhookSubscription `json:"subscription,omitempty" gorm:"foreignKey:SubscriptionID;constraint:OnDelete:CASCADE"`
+	EventID        uuid.UUID            `json:"event_id" gorm:"type:uuid;not null;index"`
+	Event          string               `json:"event" gorm:"type:varchar(50);not null"`
+	Payload        string               `json:"-" gorm:"type:jsonb;not null"`
+	Status         DeliveryStatus       `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Attempts       int                  `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int                  `json:"response_status"`
+	ResponseBody   string               `json:"response_body" gorm:"type:text"`
+	LastError      string               `json:"last_error" gorm:"type:text"`
+	NextAttemptAt  *time.Time           `json:"next_attempt_at" gorm:"index"`
+	DeliveredAt    *time.Time           `json:"delivered_at"`
+	RedeliveryOf   *uuid.UUID           `json:"redelivery_of" gorm:"type:uuid"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// WebhookPayload is the JSON body posted to subscribers
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookRequestData describes a vacation request in webhook payloads
+type WebhookRequestData struct {
+	ID           string          `json:"id"`
+	User         WebhookUserData `json:"user"`
+	StartDate    time.Time       `json:"start_date"`
+	EndDate      time.Time       `json:"end_date"`
+	BusinessDays int             `json:"business_days"`
+	LeaveType    string          `json:"leave_type"`
+	Status       string          `json:"status"`
+	Version      int             `json:"version"`
+}
+
+// WebhookUserData describes a user in webhook payloads
+type WebhookUserData struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	Department      string `json:"department"`
+	Role            string `json:"role"`
+	VacationBalance int    `json:"vacation_balance"`
+	Active          bool   `json:"active"`
+}
+
+func (u *User) ToWebhookData() WebhookUserData {
+	return WebhookUserData{
+		ID:              u.ID.String(),
+		Name:            u.Name,
+		Email:           u.Email,
+		Department:      u.Department,
+		Role:            string(u.Role),
+		VacationBalance: u.VacationBalance,
+		Active:          u.Active,
+	}
+}
+
+func (vr *VacationRequest) ToWebhookData() WebhookRequestData {
+	return WebhookRequestData{
+		ID:           vr.ID.String(),
+		User:         vr.User.ToWebhookData(),
+		StartDate:    vr.StartDate,
+		EndDate:      vr.EndDate,
+		BusinessDays: vr.BusinessDays,
+		LeaveType:    string(vr.LeaveType),
+		Status:       string(vr.Status),
+		Version:      vr.Version,
+	}
+}
+
+type CreateWebhookSubscriptionRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	// Secret is generated when empty
+	Secret string `json:"secret"`
+	Active *bool  `json:"active"`
+}
+
+type UpdateWebhookSubscriptionRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,url"`
+	Description *string  `json:"description"`
+	Events      []string `json:"events" binding:"omitempty,min=1"`
+	Secret      *string  `json:"secret" binding:"omitempty,min=16"`
+	Active      *bool    `json:"active"`
+}
+
+type WebhookSubscriptionResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ToResponse leaves the secret out; it is only shown when it is set
+func (s *WebhookSubscription) ToResponse() *WebhookSubscriptionResponse {
+	return &WebhookSubscriptionResponse{
+		ID:          s.ID.String(),
+		URL:         s.URL,
+		Description: s.Description,
+		Events:      s.EventList(),
+		Active:      s.Active,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"event_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	RedeliveryOf   *string         `json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func (d *WebhookDelivery) ToResponse() *WebhookDeliveryResponse {
+	response := &WebhookDeliveryResponse{
+		ID:             d.ID.String(),
+		EventID:        d.EventID.String(),
+		Event:          d.Event,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		LastError:      d.LastError,
+		NextAttemptAt:  d.NextAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.RedeliveryOf != nil {
+		original := d.RedeliveryOf.String()
+		response.RedeliveryOf = &original
+	}
+	return response
+}
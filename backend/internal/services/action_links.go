@@ -0,0 +1,175 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidActionToken = errors.New("invalid action token")
+	ErrActionTokenExpired = errors.New("action token has expired")
+	ErrActionTokenUsed    = errors.New("action token has already been used")
+)
+
+// ActionLinkConfig configures the signed approve/reject links added to
+// approver notifications
+type ActionLinkConfig struct {
+	Secret string
+	// BaseURL is the public endpoint the token is appended to; links are
+	// left out of notifications while it is empty
+	BaseURL string
+	TTL     time.Duration
+}
+
+var actionLinks ActionLinkConfig
+
+// ConfigureActionLinks enables decision links in approver notifications
+func ConfigureActionLinks(config ActionLinkConfig) {
+	actionLinks = config
+}
+
+// signActionToken computes the signature carried by a link. It covers every
+// field that decides what the link does, so none of them can be altered.
+func signActionToken(token *models.ActionToken) string {
+	mac := hmac.New(sha256.New, []byte(actionLinks.Secret))
+	fmt.Fprintf(mac, "%s:%s:%s:%s:%d", token.ID, token.ApprovalStepID, token.UserID, token.Action, token.ExpiresAt.Unix())
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// IssueActionLinks creates the approve and reject links of a step for one recipient
+func IssueActionLinks(db *gorm.DB, request *models.VacationRequest, step *models.ApprovalStep, userID uuid.UUID) (string, string, error) {
+	expiresAt := time.Now().Add(actionLinks.TTL).Truncate(time.Second)
+
+	var urls []string
+	for _, action := range []models.TokenAction{models.TokenApprove, models.TokenReject} {
+		token := models.ActionToken{
+			VacationRequestID: request.ID,
+			ApprovalStepID:    step.ID,
+			UserID:            userID,
+			Action:            action,
+			ExpiresAt:         expiresAt,
+		}
+		if err := db.Create(&token).Error; err != nil {
+			return "", "", err
+		}
+		urls = append(urls, fmt.Sprintf("%s/%s.%s", strings.TrimRight(actionLinks.BaseURL, "/"), token.ID, signActionToken(&token)))
+	}
+
+	return urls[0], urls[1], nil
+}
+
+// approverNotice builds a notification asking the recipient to decide a step,
+// with their decision links appended to the message and kept for templates
+func approverNotice(db *gorm.DB, request *models.VacationRequest, step *models.ApprovalStep, userID uuid.UUID, notificationType models.NotificationType, template, title, message string) *models.Notification {
+	data := map[string]string{"step": strconv.Itoa(step.Position)}
+
+	if actionLinks.BaseURL != "" {
+		approveURL, rejectURL, err := IssueActionLinks(db, request, step, userID)
+		if err != nil {
+			log.Printf("Failed to issue action links for request %s: %v", request.ID, err)
+		} else {
+			message = fmt.Sprintf("%s\n\nAprovar: %s\nRejeitar: %s", message, approveURL, rejectURL)
+			data["approve_url"] = approveURL
+			data["reject_url"] = rejectURL
+		}
+	}
+
+	return requestNotice(userID, request, notificationType, template, title, message, data)
+}
+
+// ResolveActionToken checks the signature, expiry and use of a link token
+func ResolveActionToken(db *gorm.DB, value string) (*models.ActionToken, error) {
+	idPart, signature, found := strings.Cut(value, ".")
+	if !found {
+		return nil, ErrInvalidActionToken
+	}
+	tokenID, err := uuid.Parse(idPart)
+	if err != nil {
+		return nil, ErrInvalidActionToken
+	}
+
+	var token models.ActionToken
+	if err := db.Where("id = ?", tokenID).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidActionToken
+		}
+		return nil, err
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(signActionToken(&token))) {
+		return nil, ErrInvalidActionToken
+	}
+	if token.UsedAt != nil {
+		return nil, ErrActionTokenUsed
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, ErrActionTokenExpired
+	}
+
+	return &token, nil
+}
+
+// DecideWithActionToken consumes a link token and applies its decision through
+// DecideVacationRequest, recorded as a link decision by the token's recipient
+func DecideWithActionToken(db *gorm.DB, value, comment string) (*DecisionResult, error) {
+	var result *DecisionResult
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		token, err := ResolveActionToken(tx, value)
+		if err != nil {
+			return err
+		}
+
+		// Only one request can consume the token; the sibling link dies with it
+		now := time.Now()
+		consumed := tx.Model(&models.ActionToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if consumed.Error != nil {
+			return consumed.Error
+		}
+		if consumed.RowsAffected == 0 {
+			return ErrActionTokenUsed
+		}
+		if err := tx.Model(&models.ActionToken{}).
+			Where("approval_step_id = ? AND user_id = ? AND used_at IS NULL", token.ApprovalStepID, token.UserID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		var actor models.User
+		if err := tx.Where("id = ? AND active = ?", token.UserID, true).First(&actor).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrInvalidActionToken
+			}
+			return err
+		}
+
+		result, err = DecideVacationRequest(tx, DecisionInput{
+			RequestID: token.VacationRequestID,
+			StepID:    &token.ApprovalStepID,
+			ActorID:   actor.ID,
+			ActorRole: actor.Role,
+			Approve:   token.Action == models.TokenApprove,
+			Comment:   comment,
+			Source:    models.DecisionLink,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
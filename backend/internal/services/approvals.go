@@ -0,0 +1,373 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRequestNotFound   = errors.New("vacation request not found")
+	ErrRequestNotPending = errors.New("vacation request is not pending")
+	ErrNotApprover       = errors.New("user cannot decide the current approval step")
+	ErrCommentRequired   = errors.New("comment is required for rejection")
+	ErrApprovalStarted   = errors.New("vacation request was already decided at some level")
+)
+
+// defaultApprovalSteps is the chain used when no rule matches a request
+var defaultApprovalSteps = []models.ApprovalRuleStep{
+	{Position: 1, ApproverType: models.ApproverManager},
+}
+
+// BuildApprovalSteps resolves the approval chain of a new request from the
+// configured rules. Steps whose approver cannot be resolved fall back to HR.
+func BuildApprovalSteps(db *gorm.DB, requester *models.User, request *models.VacationRequest) ([]models.ApprovalStep, error) {
+	var rules []models.ApprovalRule
+	if err := db.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Where("active = ?", true).
+		Order("priority DESC, created_at ASC").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch approval rules: %w", err)
+	}
+
+	ruleSteps := defaultApprovalSteps
+	for i := range rules {
+		if rules[i].Matches(request.BusinessDays, requester.Department, request.LeaveType) && len(rules[i].Steps) > 0 {
+			ruleSteps = rules[i].Steps
+			break
+		}
+	}
+
+	var steps []models.ApprovalStep
+	for _, ruleStep := range ruleSteps {
+		step := models.ApprovalStep{
+			VacationRequestID: request.ID,
+			ApproverType:      ruleStep.ApproverType,
+			Status:            models.StepPending,
+		}
+
+		switch ruleStep.ApproverType {
+		case models.ApproverManager:
+			step.ApproverID = requester.ManagerID
+		case models.ApproverSkipManager:
+			if requester.ManagerID != nil {
+				var manager models.User
+				if err := db.Where("id = ?", *requester.ManagerID).First(&manager).Error; err != nil && err != gorm.ErrRecordNotFound {
+					return nil, fmt.Errorf("failed to fetch manager: %w", err)
+				}
+				step.ApproverID = manager.ManagerID
+			}
+		case models.ApproverUser:
+			step.ApproverID = ruleStep.ApproverUserID
+		case models.ApproverRole:
+			step.ApproverRole = ruleStep.ApproverRole
+		}
+
+		// Nobody decides on their own request
+		if step.ApproverID != nil && *step.ApproverID == requester.ID {
+			step.ApproverID = nil
+		}
+		if step.ApproverID == nil && step.ApproverRole == "" {
+			step.ApproverRole = models.RoleHR
+		}
+
+		// Collapse consecutive steps assigned to the same approver
+		if n := len(steps); n > 0 && sameApprover(&steps[n-1], &step) {
+			continue
+		}
+
+		step.Position = len(steps) + 1
+		steps = append(steps, step)
+	}
+
+	now := time.Now()
+	steps[0].ActivatedAt = &now
+
+	return steps, nil
+}
+
+func sameApprover(a, b *models.ApprovalStep) bool {
+	if a.ApproverID != nil || b.ApproverID != nil {
+		return a.ApproverID != nil && b.ApproverID != nil && *a.ApproverID == *b.ApproverID
+	}
+	return a.ApproverRole == b.ApproverRole
+}
+
+// managerOnlyChain reports whether the chain is a single step assigned to the manager
+func managerOnlyChain(steps []models.ApprovalStep, managerID uuid.UUID) bool {
+	return len(steps) == 1 && steps[0].ApproverID != nil && *steps[0].ApproverID == managerID
+}
+
+// CurrentApprovalStep returns the first pending step of a chain ordered by position
+func CurrentApprovalStep(steps []models.ApprovalStep) *models.ApprovalStep {
+	for i := range steps {
+		if steps[i].Status == models.StepPending {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+type DecisionInput struct {
+	RequestID uuid.UUID
+	ActorID   uuid.UUID
+	ActorRole models.UserRole
+	Approve   bool
+	Comment   string
+	// StepID, when set, makes the decision apply only while that step is
+	// still the one awaiting a decision
+	StepID *uuid.UUID
+	// Version, when set, makes the decision apply only to that version of
+	// the request
+	Version *int
+	// Source defaults to a manual decision; system decisions skip the
+	// approver checks and settle every remaining step at once
+	Source models.DecisionSource
+	// AutoApprovalRule, on a system approval, records the team rule that
+	// approved the request and opens its revoke window. The rule only stands
+	// in for its manager, so the chain must be that manager's single step.
+	AutoApprovalRule *models.AutoApprovalRule
+}
+
+type DecisionResult struct {
+	Request *models.VacationRequest
+	Step    *models.ApprovalStep
+	// Next is the step now awaiting a decision, if any
+	Next *models.ApprovalStep
+	// Final is true when the decision moved the request out of pending
+	Final bool
+}
+
+// DecideVacationRequest records the actor's decision on the current approval
+// step. A rejection ends the chain; the request is approved when the last step passes.
+// The requester and the next approver hear about it through the outbox.
+func DecideVacationRequest(db *gorm.DB, input DecisionInput) (*DecisionResult, error) {
+	// The requester is always told why a request was rejected
+	if !input.Approve && input.Comment == "" {
+		return nil, ErrCommentRequired
+	}
+
+	result := &DecisionResult{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Decisions on the same request are serialized by the row lock
+		var request models.VacationRequest
+		if err := tx.Clauses(lockForUpdate).Preload("User").Preload("ApprovalSteps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).Where("id = ?", input.RequestID).First(&request).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrRequestNotFound
+			}
+			return err
+		}
+
+		event := models.EventApprove
+		if !input.Approve {
+			event = models.EventReject
+		}
+		if err := request.Can(event, time.Now()); err != nil {
+			return ErrRequestNotPending
+		}
+		if input.Version != nil && *input.Version != request.Version {
+			return ErrRequestChanged
+		}
+		previous := request
+
+		source := input.Source
+		if source == "" {
+			source = models.DecisionManual
+		}
+
+		step := CurrentApprovalStep(request.ApprovalSteps)
+		if step == nil || (input.StepID != nil && step.ID != *input.StepID) {
+			return ErrNotApprover
+		}
+		if input.AutoApprovalRule != nil && !managerOnlyChain(request.ApprovalSteps, input.AutoApprovalRule.ManagerID) {
+			return ErrNotApprover
+		}
+
+		now := time.Now()
+		var decidedBy, onBehalfOf *uuid.UUID
+		if source != models.DecisionSystem {
+			if request.UserID == input.ActorID {
+				return ErrNotApprover
+			}
+
+			// Delegates decide on behalf of the assigned approver
+			if !step.CanBeDecidedBy(input.ActorID, input.ActorRole) {
+				if step.ApproverID == nil {
+					return ErrNotApprover
+				}
+				delegateID, err := ActiveDelegate(tx, *step.ApproverID, now)
+				if err != nil {
+					return err
+				}
+				if delegateID == nil || *delegateID != input.ActorID {
+					return ErrNotApprover
+				}
+				onBehalfOf = step.ApproverID
+			}
+			decidedBy = &input.ActorID
+		}
+
+		stepStatus := models.StepApproved
+		if !input.Approve {
+			stepStatus = models.StepRejected
+		}
+
+		// A system approval settles every remaining level at once
+		for current := step; current != nil; current = CurrentApprovalStep(request.ApprovalSteps) {
+			current.Status = stepStatus
+			current.DecidedBy = decidedBy
+			current.DecidedAt = &now
+			current.OnBehalfOf = onBehalfOf
+			current.DecisionSource = source
+			current.Comment = input.Comment
+			if err := tx.Omit("Approver").Save(current).Error; err != nil {
+				return err
+			}
+
+			if source != models.DecisionSystem || !input.Approve {
+				break
+			}
+		}
+
+		next := CurrentApprovalStep(request.ApprovalSteps)
+
+		if !input.Approve {
+			// Remaining levels are no longer needed
+			if err := tx.Model(&models.ApprovalStep{}).
+				Where("vacation_request_id = ? AND status = ?", request.ID, models.StepPending).
+				Update("status", models.StepSkipped).Error; err != nil {
+				return err
+			}
+		}
+		result.Final = !input.Approve || next == nil
+
+		// The next level's SLA starts counting now
+		if !result.Final && next != nil {
+			next.ActivatedAt = &now
+			if err := tx.Model(next).Update("activated_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		if result.Final {
+			effect, err := request.Apply(event, now)
+			if err != nil {
+				return err
+			}
+			if err := ApplyBalance(tx, request.UserID, effect.BalanceDelta); err != nil {
+				return err
+			}
+
+			request.ApprovedBy = decidedBy
+			request.ApprovedOnBehalfOf = onBehalfOf
+			request.ApprovalDate = &now
+			request.ApprovalComment = input.Comment
+			if rule := input.AutoApprovalRule; rule != nil && input.Approve {
+				revocableUntil := now.Add(time.Duration(rule.RevokeWindowHours) * time.Hour)
+				request.AutoApprovalRuleID = &rule.ID
+				request.RevocableUntil = &revocableUntil
+			}
+			if err := SaveVacationRequest(tx, &request); err != nil {
+				return err
+			}
+		}
+
+		action := models.HistoryStepApproved
+		switch {
+		case !input.Approve:
+			action = models.HistoryRejected
+		case source == models.DecisionSystem:
+			action = models.HistoryAutoApproved
+		case result.Final:
+			action = models.HistoryApproved
+		}
+		if err := RecordHistory(tx, models.RequestHistory{
+			VacationRequestID: request.ID,
+			ActorID:           decidedBy,
+			OnBehalfOf:        onBehalfOf,
+			Action:            action,
+			Source:            source,
+			Comment:           input.Comment,
+		}, &previous, &request); err != nil {
+			return err
+		}
+
+		result.Request = &request
+		result.Step = step
+		if !result.Final {
+			result.Next = next
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// LockDecidedSteps counts the steps of the request that were already decided,
+// holding the request lock so no decision is recorded until the transaction ends
+func LockDecidedSteps(tx *gorm.DB, requestID uuid.UUID) (int64, error) {
+	if err := tx.Clauses(lockForUpdate).Select("id").Where("id = ?", requestID).
+		First(&models.VacationRequest{}).Error; err != nil {
+		return 0, err
+	}
+	var decided int64
+	err := tx.Model(&models.ApprovalStep{}).
+		Where("vacation_request_id = ? AND status <> ?", requestID, models.StepPending).
+		Count(&decided).Error
+	return decided, err
+}
+
+// NotifyNextApprover tells the approver of the next level that the request awaits them
+func NotifyNextApprover(db *gorm.DB, request *models.VacationRequest, step *models.ApprovalStep) {
+	message := fmt.Sprintf("A solicitação de férias de %s (%s a %s) aguarda sua aprovação (etapa %d).",
+		request.User.Name,
+		request.StartDate.Format("02/01/2006"),
+		request.EndDate.Format("02/01/2006"),
+		step.Position)
+
+	for _, recipient := range approverRecipients(db, step) {
+		if err := Deliver(db, approverNotice(db, request, step, recipient, models.NotificationRequest,
+			models.TemplateRequestSubmitted, "Aprovação Pendente", message)); err != nil {
+			log.Printf("Failed to notify approver for request %s: %v", request.ID, err)
+		}
+	}
+}
+
+// approverRecipients resolves who should hear about a step: its approver and
+// current delegate, or every active user holding the step role
+func approverRecipients(db *gorm.DB, step *models.ApprovalStep) []uuid.UUID {
+	var recipients []uuid.UUID
+	if step.ApproverID != nil {
+		recipients = append(recipients, *step.ApproverID)
+		delegateID, err := ActiveDelegate(db, *step.ApproverID, time.Now())
+		if err != nil {
+			log.Printf("Failed to resolve delegate of approver %s: %v", *step.ApproverID, err)
+		} else if delegateID != nil {
+			recipients = append(recipients, *delegateID)
+		}
+		return recipients
+	}
+
+	roles := []models.UserRole{step.ApproverRole}
+	if step.ApproverRole == models.RoleHR {
+		roles = append(roles, models.RoleAdmin)
+	}
+	if err := db.Model(&models.User{}).
+		Where("role IN ? AND active = ?", roles, true).
+		Pluck("id", &recipients).Error; err != nil {
+		log.Printf("Failed to resolve approvers with role %s: %v", step.ApproverRole, err)
+	}
+	return recipients
+}
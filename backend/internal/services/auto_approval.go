@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotAutoApproved     = errors.New("vacation request was not auto-approved")
+	ErrRevokeWindowClosed  = errors.New("revoke window has closed")
+	ErrNotRequesterManager = errors.New("user is not the requester's manager")
+)
+
+// FindAutoApprovalRule returns the first rule of the requester's manager that
+// allows the request to be approved without review, if any. Rules only stand
+// in for the manager: requests whose chain has other levels, such as HR for
+// long leaves, always go through review.
+func FindAutoApprovalRule(db *gorm.DB, requester *models.User, request *models.VacationRequest) (*models.AutoApprovalRule, error) {
+	if requester.ManagerID == nil {
+		return nil, nil
+	}
+
+	var steps []models.ApprovalStep
+	if err := db.Where("vacation_request_id = ?", request.ID).Find(&steps).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch approval steps: %w", err)
+	}
+	if !managerOnlyChain(steps, *requester.ManagerID) {
+		return nil, nil
+	}
+
+	var rules []models.AutoApprovalRule
+	if err := db.Where("manager_id = ? AND active = ?", *requester.ManagerID, true).
+		Order("created_at ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch auto-approval rules: %w", err)
+	}
+
+	now := time.Now()
+	for i := range rules {
+		if !rules[i].Matches(request, now) {
+			continue
+		}
+
+		if rules[i].RequireNoConflict {
+			conflict, err := hasTeamConflict(db, requester, request)
+			if err != nil {
+				return nil, err
+			}
+			if conflict {
+				continue
+			}
+		}
+
+		return &rules[i], nil
+	}
+
+	return nil, nil
+}
+
+// hasTeamConflict reports whether a teammate is already off, or asking to be,
+// during the requested period
+func hasTeamConflict(db *gorm.DB, requester *models.User, request *models.VacationRequest) (bool, error) {
+	var count int64
+	if err := db.Model(&models.VacationRequest{}).
+		Joins("JOIN users ON users.id = vacation_requests.user_id").
+		Where("users.manager_id = ? AND vacation_requests.user_id <> ?", *requester.ManagerID, requester.ID).
+		Where("vacation_requests.status IN ? AND vacation_requests.start_date <= ? AND vacation_requests.end_date >= ?",
+			models.ReservedStatuses, request.EndDate, request.StartDate).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check team conflicts: %w", err)
+	}
+	return count > 0, nil
+}
+
+// AutoApprove approves the request on behalf of the system and opens the
+// manager's revoke window. The requester and the manager hear about it
+// through the outbox.
+func AutoApprove(db *gorm.DB, request *models.VacationRequest, rule *models.AutoApprovalRule) error {
+	_, err := DecideVacationRequest(db, DecisionInput{
+		RequestID:        request.ID,
+		Approve:          true,
+		Comment:          fmt.Sprintf("Aprovada automaticamente pela regra \"%s\"", rule.Name),
+		Source:           models.DecisionSystem,
+		AutoApprovalRule: rule,
+	})
+	return err
+}
+
+// RevokeAutoApproval turns an auto-approved request into a rejection while the
+// revoke window is open and gives the days back to the requester
+func RevokeAutoApproval(db *gorm.DB, requestID, actorID uuid.UUID, actorRole models.UserRole, comment string) (*models.VacationRequest, error) {
+	var request models.VacationRequest
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(lockForUpdate).Preload("User").Where("id = ?", requestID).First(&request).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrRequestNotFound
+			}
+			return err
+		}
+
+		if actorRole != models.RoleAdmin && actorRole != models.RoleHR && (request.User.ManagerID == nil || *request.User.ManagerID != actorID) {
+			return ErrNotRequesterManager
+		}
+		now := time.Now()
+		if request.AutoApprovalRuleID == nil || request.Can(models.EventRevoke, now) != nil {
+			return ErrNotAutoApproved
+		}
+		if request.RevocableUntil == nil || now.After(*request.RevocableUntil) {
+			return ErrRevokeWindowClosed
+		}
+
+		previous := request
+		effect, err := request.Apply(models.EventRevoke, now)
+		if err != nil {
+			return err
+		}
+		request.ApprovedBy = &actorID
+		request.ApprovalDate = &now
+		request.ApprovalComment = comment
+		if err := SaveVacationRequest(tx, &request); err != nil {
+			return err
+		}
+
+		if err := RecordHistory(tx, models.RequestHistory{
+			VacationRequestID: request.ID,
+			ActorID:           &actorID,
+			Action:            models.HistoryRevoked,
+			Comment:           comment,
+		}, &previous, &request); err != nil {
+			return err
+		}
+
+		return ApplyBalance(tx, request.UserID, effect.BalanceDelta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// InAppChannel is the notification center. The stored notification already
+// is the delivery, so sending only confirms it.
+type InAppChannel struct{}
+
+func (InAppChannel) Name() string {
+	return models.ChannelInApp
+}
+
+func (InAppChannel) Send(ctx context.Context, recipient *models.User, notification *models.Notification) error {
+	return nil
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// EmailChannel sends notifications as email, rendered from the templates in
+// the recipient's locale with a plain-text and an HTML part
+type EmailChannel struct {
+	db     *gorm.DB
+	config SMTPConfig
+}
+
+func NewEmailChannel(db *gorm.DB, config SMTPConfig) *EmailChannel {
+	return &EmailChannel{db: db, config: config}
+}
+
+func (*EmailChannel) Name() string {
+	return models.ChannelEmail
+}
+
+func (ch *EmailChannel) Send(ctx context.Context, recipient *models.User, notification *models.Notification) error {
+	if recipient.Email == "" {
+		return errors.New("recipient has no email address")
+	}
+
+	email, err := RenderNotificationEmail(ch.db, recipient, notification)
+	if err != nil {
+		return err
+	}
+	return ch.send(ctx, recipient, email)
+}
+
+// SendDigest emails the held notifications together
+func (ch *EmailChannel) SendDigest(ctx context.Context, recipient *models.User, settings *models.NotificationSettings, notifications []models.Notification) error {
+	if recipient.Email == "" {
+		return errors.New("recipient has no email address")
+	}
+
+	email, err := RenderDigestEmail(recipient, settings, notifications)
+	if err != nil {
+		return err
+	}
+	return ch.send(ctx, recipient, email)
+}
+
+func (ch *EmailChannel) send(ctx context.Context, recipient *models.User, email *RenderedEmail) error {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", email.Text},
+		{"text/html; charset=UTF-8", email.HTML},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return err
+		}
+		if err := encoder.Close(); err != nil {
+			return err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return err
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", ch.config.From)
+	fmt.Fprintf(&message, "To: %s\r\n", recipient.Email)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	message.Write(body.Bytes())
+
+	var auth smtp.Auth
+	if ch.config.Username != "" {
+		auth = smtp.PlainAuth("", ch.config.Username, ch.config.Password, ch.config.Host)
+	}
+	addr := net.JoinHostPort(ch.config.Host, strconv.Itoa(ch.config.Port))
+
+	// net/smtp takes no context; the attempt is abandoned when it expires
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, ch.config.From, []string{recipient.Email}, message.Bytes())
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WebhookChannel posts notifications as JSON to a fixed URL, for chat bridges
+// and other integrations
+type WebhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookChannel(url string) *WebhookChannel {
+	return &WebhookChannel{url: url, client: &http.Client{}}
+}
+
+func (*WebhookChannel) Name() string {
+	return models.ChannelWebhook
+}
+
+type webhookRecipient struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type webhookNotification struct {
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	Title     string           `json:"title"`
+	Message   string           `json:"message"`
+	Recipient webhookRecipient `json:"recipient"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func (ch *WebhookChannel) Send(ctx context.Context, recipient *models.User, notification *models.Notification) error {
+	body, err := json.Marshal(webhookNotification{
+		ID:      notification.ID.String(),
+		Type:    string(notification.Type),
+		Title:   notification.Title,
+		Message: notification.Message,
+		Recipient: webhookRecipient{
+			ID:    recipient.ID.String(),
+			Name:  recipient.Name,
+			Email: recipient.Email,
+		},
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ch.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
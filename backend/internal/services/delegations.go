@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// startOfDay truncates a time to midnight so that a leave ending today still counts
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// explicitDelegate returns the delegate configured by the approver for the given moment
+func explicitDelegate(db *gorm.DB, approverID uuid.UUID, at time.Time) (*uuid.UUID, error) {
+	var delegation models.ApprovalDelegation
+	err := db.Where("delegator_id = ? AND revoked_at IS NULL AND start_date <= ? AND end_date >= ?",
+		approverID, at, startOfDay(at)).
+		Order("created_at DESC").
+		First(&delegation).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delegation.DelegateID, nil
+}
+
+// automaticDelegate returns who covers an approver currently on approved leave:
+// the accepted substitute of that leave, otherwise the approver's own manager
+func automaticDelegate(db *gorm.DB, approverID uuid.UUID, at time.Time) (*uuid.UUID, error) {
+	var leave models.VacationRequest
+	err := db.Preload("User").
+		Where("user_id = ? AND status IN ? AND start_date <= ? AND end_date >= ?",
+			approverID, models.OnLeaveStatuses, at, startOfDay(at)).
+		First(&leave).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if leave.SubstituteID != nil && leave.SubstituteStatus == models.SubstituteAccepted {
+		return leave.SubstituteID, nil
+	}
+	return leave.User.ManagerID, nil
+}
+
+// ActiveDelegate returns the user currently deciding on behalf of the approver, if any.
+// An explicit delegation takes precedence over the automatic one during leave.
+func ActiveDelegate(db *gorm.DB, approverID uuid.UUID, at time.Time) (*uuid.UUID, error) {
+	delegateID, err := explicitDelegate(db, approverID, at)
+	if err != nil || delegateID != nil {
+		return delegateID, err
+	}
+	return automaticDelegate(db, approverID, at)
+}
+
+// ActiveDelegators returns the approvers on whose behalf the user may currently decide
+func ActiveDelegators(db *gorm.DB, delegateID uuid.UUID, at time.Time) ([]uuid.UUID, error) {
+	candidates := map[uuid.UUID]bool{}
+
+	var explicit []uuid.UUID
+	if err := db.Model(&models.ApprovalDelegation{}).
+		Where("delegate_id = ? AND revoked_at IS NULL AND start_date <= ? AND end_date >= ?",
+			delegateID, at, startOfDay(at)).
+		Pluck("delegator_id", &explicit).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range explicit {
+		candidates[id] = true
+	}
+
+	// People on leave who chose the user as substitute or who report to the user
+	var onLeave []uuid.UUID
+	if err := db.Model(&models.VacationRequest{}).
+		Joins("JOIN users ON users.id = vacation_requests.user_id").
+		Where("vacation_requests.status IN ? AND vacation_requests.start_date <= ? AND vacation_requests.end_date >= ?",
+			models.OnLeaveStatuses, at, startOfDay(at)).
+		Where("vacation_requests.substitute_id = ? OR users.manager_id = ?", delegateID, delegateID).
+		Pluck("vacation_requests.user_id", &onLeave).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range onLeave {
+		candidates[id] = true
+	}
+
+	// Confirm each candidate really resolves to the user right now
+	var delegators []uuid.UUID
+	for id := range candidates {
+		if id == delegateID {
+			continue
+		}
+		current, err := ActiveDelegate(db, id, at)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil && *current == delegateID {
+			delegators = append(delegators, id)
+		}
+	}
+
+	return delegators, nil
+}
+
+// notifyDelegation tells the delegate whose approvals they take over and when
+func notifyDelegation(db *gorm.DB, delegation *models.ApprovalDelegation) {
+	notifyUsers(db, []uuid.UUID{delegation.DelegateID}, models.NotificationSystem, "Delegação de Aprovação",
+		fmt.Sprintf("%s delegou a você a aprovação de solicitações de férias de %s a %s.",
+			delegation.Delegator.Name,
+			delegation.StartDate.Format("02/01/2006"),
+			delegation.EndDate.Format("02/01/2006")))
+}
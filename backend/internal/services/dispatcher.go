@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Channel delivers stored notifications through one medium. New channels are
+// registered on the Dispatcher; callers keep using Notify.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, recipient *models.User, notification *models.Notification) error
+}
+
+// DigestChannel is a channel that can also send many notifications at once
+type DigestChannel interface {
+	Channel
+	SendDigest(ctx context.Context, recipient *models.User, settings *models.NotificationSettings, notifications []models.Notification) error
+}
+
+// LoadNotificationSettings returns the user's settings, or the defaults when
+// they never changed them
+func LoadNotificationSettings(db *gorm.DB, userID uuid.UUID) (*models.NotificationSettings, error) {
+	var settings models.NotificationSettings
+	if err := db.Where("user_id = ?", userID).First(&settings).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("failed to fetch notification settings: %w", err)
+		}
+		settings = models.DefaultNotificationSettings(userID)
+	}
+	return &settings, nil
+}
+
+type DispatcherConfig struct {
+	// DefaultChannels deliver every notification type without a rule of its own
+	DefaultChannels []string
+	// EventChannels replaces the default channels for a notification type
+	EventChannels map[models.NotificationType][]string
+	// MaxAttempts is how many times a delivery is tried before it is failed
+	MaxAttempts int
+	// RetryDelay is the wait after the first failure, doubled on each retry
+	RetryDelay time.Duration
+	// SendTimeout bounds a single attempt
+	SendTimeout time.Duration
+	BatchSize   int
+}
+
+// Dispatcher records which channels each notification goes through and
+// delivers them in the background, retrying failed attempts
+type Dispatcher struct {
+	db       *gorm.DB
+	config   DispatcherConfig
+	channels map[string]Channel
+	interval time.Duration
+	wake     chan struct{}
+}
+
+func NewDispatcher(db *gorm.DB, config DispatcherConfig, interval time.Duration, channels ...Channel) *Dispatcher {
+	d := &Dispatcher{
+		db:       db,
+		config:   config,
+		channels: map[string]Channel{},
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+	}
+	for _, channel := range channels {
+		d.channels[channel.Name()] = channel
+	}
+	return d
+}
+
+var dispatcher *Dispatcher
+
+// SetDispatcher makes Notify hand new notifications to the dispatcher
+func SetDispatcher(d *Dispatcher) {
+	dispatcher = d
+}
+
+// channelsFor resolves the channels of a notification: those configured for
+// its type, minus the ones the recipient switched off. The in-app channel is
+// the notification center itself and is always used.
+func (d *Dispatcher) channelsFor(db *gorm.DB, notification *models.Notification) ([]string, error) {
+	configured, ok := d.config.EventChannels[notification.Type]
+	if !ok {
+		configured = d.config.DefaultChannels
+	}
+
+	var preferences []models.NotificationChannelPreference
+	if err := db.Where("user_id = ? AND type IN ?", notification.UserID, []models.NotificationType{"", notification.Type}).
+		Find(&preferences).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch channel preferences: %w", err)
+	}
+	// A preference for the type wins over one for every type
+	enabled := map[string]bool{}
+	for _, preference := range preferences {
+		if preference.Type == "" {
+			enabled[preference.Channel] = preference.Enabled
+		}
+	}
+	for _, preference := range preferences {
+		if preference.Type != "" {
+			enabled[preference.Channel] = preference.Enabled
+		}
+	}
+
+	channels := []string{models.ChannelInApp}
+	for _, name := range configured {
+		if name == models.ChannelInApp || d.channels[name] == nil {
+			continue
+		}
+		if on, set := enabled[name]; set && !on {
+			continue
+		}
+		channels = append(channels, name)
+	}
+	return channels, nil
+}
+
+// Enqueue records a pending delivery of the notification on each of its
+// channels. Deliveries are written with db, so they commit together with the
+// notification when it is created inside a transaction. Outside the app,
+// deliveries wait for the recipient's quiet hours to end, and non-urgent
+// emails are held for their digest.
+func (d *Dispatcher) Enqueue(db *gorm.DB, notification *models.Notification) error {
+	channels, err := d.channelsFor(db, notification)
+	if err != nil {
+		return err
+	}
+	settings, err := LoadNotificationSettings(db, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	quietUntil := settings.QuietUntil(now)
+	for _, name := range channels {
+		delivery := models.NotificationDelivery{
+			NotificationID: notification.ID,
+			Channel:        name,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  &now,
+		}
+		if name != models.ChannelInApp {
+			_, digest := d.channels[name].(DigestChannel)
+			switch {
+			case digest && settings.Digest != models.DigestOff && !notification.Type.Urgent():
+				delivery.Status = models.DeliveryDigest
+				delivery.NextAttemptAt = nil
+			case quietUntil != nil:
+				delivery.NextAttemptAt = quietUntil
+			}
+		}
+		if err := db.Create(&delivery).Error; err != nil {
+			return fmt.Errorf("failed to enqueue %s delivery: %w", name, err)
+		}
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start delivers due notifications periodically, and as soon as new ones are
+// enqueued, until the context is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Run(); err != nil {
+			log.Printf("Notification dispatch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// Run attempts every delivery that is due and sends the digests
+func (d *Dispatcher) Run() error {
+	if err := d.sendDigests(time.Now()); err != nil {
+		log.Printf("Notification digests failed: %v", err)
+	}
+
+	for {
+		deliveries, err := d.claim()
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		for i := range deliveries {
+			d.deliver(&deliveries[i])
+		}
+	}
+}
+
+// claim picks a batch of due deliveries and pushes their next attempt out, so
+// other instances skip them while they are being sent
+func (d *Dispatcher) claim() ([]models.NotificationDelivery, error) {
+	var deliveries []models.NotificationDelivery
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(lockSkipLocked).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+			Order("next_attempt_at ASC").
+			Limit(d.config.BatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]interface{}, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		lease := time.Now().Add(d.config.SendTimeout + time.Minute)
+		return tx.Model(&models.NotificationDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", lease).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// deliver makes one attempt and records its outcome
+func (d *Dispatcher) deliver(delivery *models.NotificationDelivery) {
+	notification, sendErr := d.send(delivery)
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts": delivery.Attempts + 1,
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.DeliverySent
+		updates["sent_at"] = now
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = ""
+	case delivery.Attempts+1 >= d.config.MaxAttempts:
+		updates["status"] = models.DeliveryFailed
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = sendErr.Error()
+	default:
+		next := now.Add(d.config.RetryDelay << delivery.Attempts)
+		if notification != nil {
+			// Retries respect quiet hours too
+			if settings, err := LoadNotificationSettings(d.db, notification.UserID); err == nil {
+				if until := settings.QuietUntil(next); until != nil {
+					next = *until
+				}
+			}
+		}
+		updates["next_attempt_at"] = next
+		updates["last_error"] = sendErr.Error()
+	}
+	if sendErr != nil {
+		log.Printf("Failed to deliver notification %s through %s (attempt %d): %v",
+			delivery.NotificationID, delivery.Channel, delivery.Attempts+1, sendErr)
+	}
+
+	if err := d.db.Model(delivery).Updates(updates).Error; err != nil {
+		log.Printf("Failed to record delivery %s: %v", delivery.ID, err)
+	}
+}
+
+func (d *Dispatcher) send(delivery *models.NotificationDelivery) (*models.Notification, error) {
+	var notification models.Notification
+	if err := d.db.Preload("User").Where("id = ?", delivery.NotificationID).First(&notification).Error; err != nil {
+		return nil, fmt.Errorf("failed to load notification: %w", err)
+	}
+
+	channel := d.channels[delivery.Channel]
+	if channel == nil {
+		return &notification, fmt.Errorf("channel %s is not configured", delivery.Channel)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.SendTimeout)
+	defer cancel()
+	return &notification, channel.Send(ctx, &notification.User, &notification)
+}
+
+// sendDigests sends the held deliveries of every recipient whose digest is
+// due. Deliveries held for someone who turned the digest off are released to
+// be sent one by one.
+func (d *Dispatcher) sendDigests(now time.Time) error {
+	var recipients []uuid.UUID
+	if err := d.db.Model(&models.NotificationDelivery{}).
+		Joins("JOIN notifications ON notifications.id = notification_deliveries.notification_id").
+		Where("notification_deliveries.status = ?", models.DeliveryDigest).
+		Distinct().Pluck("notifications.user_id", &recipients).Error; err != nil {
+		return fmt.Errorf("failed to fetch digest recipients: %w", err)
+	}
+
+	for _, userID := range recipients {
+		if err := d.sendDigest(userID, now); err != nil {
+			log.Printf("Failed to send digest to user %s: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) sendDigest(userID uuid.UUID, now time.Time) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		held := tx.Model(&models.NotificationDelivery{}).
+			Where("status = ? AND notification_id IN (?)", models.DeliveryDigest,
+				tx.Model(&models.Notification{}).Select("id").Where("user_id = ?", userID))
+
+		// Another instance sending the same digest holds the lock
+		var settings models.NotificationSettings
+		err := tx.Clauses(lockSkipLocked).Where("user_id = ?", userID).First(&settings).Error
+		if err == gorm.ErrRecordNotFound || (err == nil && settings.Digest == models.DigestOff) {
+			return held.Updates(map[string]interface{}{
+				"status":          models.DeliveryPending,
+				"next_attempt_at": now,
+			}).Error
+		}
+		if err != nil || !settings.DigestDue(now) {
+			return err
+		}
+
+		var deliveries []models.NotificationDelivery
+		if err := held.Session(&gorm.Session{}).Preload("Notification").Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		// A digest that failed waits for its next attempt like any delivery
+		attempts := 0
+		for _, delivery := range deliveries {
+			if delivery.NextAttemptAt != nil && delivery.NextAttemptAt.After(now) {
+				return nil
+			}
+			if delivery.Attempts > attempts {
+				attempts = delivery.Attempts
+			}
+		}
+
+		channel, ok := d.channels[deliveries[0].Channel].(DigestChannel)
+		if !ok {
+			return fmt.Errorf("channel %s cannot send digests", deliveries[0].Channel)
+		}
+		var recipient models.User
+		if err := tx.Where("id = ?", userID).First(&recipient).Error; err != nil {
+			return err
+		}
+		notifications := make([]models.Notification, 0, len(deliveries))
+		ids := make([]uuid.UUID, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			notifications = append(notifications, *delivery.Notification)
+			ids = append(ids, delivery.ID)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), d.config.SendTimeout)
+		defer cancel()
+		sendErr := channel.SendDigest(ctx, &recipient, &settings, notifications)
+
+		if sendErr == nil {
+			if err := tx.Model(&models.NotificationDelivery{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+				"status":          models.DeliverySent,
+				"attempts":        gorm.Expr("attempts + 1"),
+				"sent_at":         now,
+				"next_attempt_at": nil,
+				"last_error":      "",
+			}).Error; err != nil {
+				return err
+			}
+			return tx.Model(&settings).Update("last_digest_at", now).Error
+		}
+
+		// The digest stays due and is tried again after the retry delay. Items
+		// that ran out of attempts are failed and the rest wait for the retry.
+		log.Printf("Failed to send digest to user %s (attempt %d): %v", userID, attempts+1, sendErr)
+		var exhausted, retried []uuid.UUID
+		for _, delivery := range deliveries {
+			if delivery.Attempts+1 >= d.config.MaxAttempts {
+				exhausted = append(exhausted, delivery.ID)
+			} else {
+				retried = append(retried, delivery.ID)
+			}
+		}
+		if len(exhausted) > 0 {
+			if err := tx.Model(&models.NotificationDelivery{}).Where("id IN ?", exhausted).Updates(map[string]interface{}{
+				"status":          models.DeliveryFailed,
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": nil,
+				"last_error":      sendErr.Error(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if len(retried) == 0 {
+			// Nothing is left to retry, so this digest is over
+			return tx.Model(&settings).Update("last_digest_at", now).Error
+		}
+		return tx.Model(&models.NotificationDelivery{}).Where("id IN ?", retried).Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": now.Add(d.config.RetryDelay << attempts),
+			"last_error":      sendErr.Error(),
+		}).Error
+	})
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultLocale is used for recipients whose locale has no templates
+const DefaultLocale = "pt-BR"
+
+// EmailLocales lists the locales with email templates
+var EmailLocales = []string{"pt-BR", "en"}
+
+// TemplateGeneric renders notifications without a lifecycle template
+const TemplateGeneric = "generic"
+
+var ErrUnknownEmailTemplate = errors.New("unknown email template")
+
+//go:embed templates/email
+var emailTemplateFiles embed.FS
+
+var emailDateFormats = map[string]string{
+	"pt-BR": "02/01/2006",
+	"en":    "Jan 2, 2006",
+}
+
+type localeTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Templates are compiled into the binary, so a broken one stops the server at
+// start-up instead of failing deliveries
+var emailTemplates = parseEmailTemplates()
+
+func parseEmailTemplates() map[string]localeTemplates {
+	templates := map[string]localeTemplates{}
+	for _, locale := range EmailLocales {
+		dateFormat := emailDateFormats[locale]
+		funcs := map[string]interface{}{
+			"date":  func(t time.Time) string { return t.Format(dateFormat) },
+			"lines": func(s string) []string { return strings.Split(s, "\n") },
+		}
+		templates[locale] = localeTemplates{
+			text: texttemplate.Must(texttemplate.New(locale).Funcs(funcs).
+				ParseFS(emailTemplateFiles, "templates/email/"+locale+".txt")),
+			html: htmltemplate.Must(htmltemplate.New(locale).Funcs(funcs).
+				ParseFS(emailTemplateFiles, "templates/email/"+locale+".html")),
+		}
+	}
+	return templates
+}
+
+var emailAppURL string
+
+// ConfigureEmail sets the frontend URL linked from every email
+func ConfigureEmail(appURL string) {
+	emailAppURL = strings.TrimRight(appURL, "/")
+}
+
+// EmailData is what the templates render. Request is nil for generic
+// notifications and Items is only set for digests.
+type EmailData struct {
+	Recipient *models.User
+	Request   *models.VacationRequest
+	Items     []models.Notification
+	Subject   string
+	Title     string
+	Message   string
+	Data      map[string]string
+	AppURL    string
+}
+
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailLocale picks the templates for a user locale: an exact match, then one
+// for the same language, then the default
+func EmailLocale(locale string) string {
+	if _, ok := emailTemplates[locale]; ok {
+		return locale
+	}
+	language := strings.ToLower(strings.SplitN(strings.ReplaceAll(locale, "_", "-"), "-", 2)[0])
+	for _, candidate := range EmailLocales {
+		if strings.ToLower(strings.SplitN(candidate, "-", 2)[0]) == language {
+			return candidate
+		}
+	}
+	return DefaultLocale
+}
+
+// RenderEmail renders the subject and both bodies of a template
+func RenderEmail(locale, name string, data *EmailData) (*RenderedEmail, error) {
+	templates := emailTemplates[EmailLocale(locale)]
+	if templates.text.Lookup(name) == nil || templates.html.Lookup(name) == nil {
+		return nil, ErrUnknownEmailTemplate
+	}
+	if data.AppURL == "" {
+		data.AppURL = emailAppURL
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := templates.text.ExecuteTemplate(&subject, name+".subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+	data.Subject = strings.TrimSpace(subject.String())
+	if err := templates.text.ExecuteTemplate(&text, name, data); err != nil {
+		return nil, fmt.Errorf("failed to render text body: %w", err)
+	}
+	if err := templates.html.ExecuteTemplate(&html, name, data); err != nil {
+		return nil, fmt.Errorf("failed to render HTML body: %w", err)
+	}
+
+	return &RenderedEmail{
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// RenderNotificationEmail renders a notification in the recipient's locale,
+// with its lifecycle template when it has one
+func RenderNotificationEmail(db *gorm.DB, recipient *models.User, notification *models.Notification) (*RenderedEmail, error) {
+	data := &EmailData{
+		Recipient: recipient,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		Data:      notification.TemplateData(),
+	}
+
+	name := TemplateGeneric
+	if notification.Template != "" && notification.VacationRequestID != nil {
+		var request models.VacationRequest
+		if err := db.Preload("User").Where("id = ?", *notification.VacationRequestID).First(&request).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				return nil, fmt.Errorf("failed to load vacation request: %w", err)
+			}
+		} else {
+			data.Request = &request
+			name = notification.Template
+		}
+	}
+
+	return RenderEmail(recipient.Locale, name, data)
+}
+
+// RenderDigestEmail renders the notifications held for a digest, converting
+// their times to the recipient's time zone
+func RenderDigestEmail(recipient *models.User, settings *models.NotificationSettings, notifications []models.Notification) (*RenderedEmail, error) {
+	location := settings.Location()
+	items := make([]models.Notification, len(notifications))
+	for i, notification := range notifications {
+		items[i] = notification
+		items[i].CreatedAt = notification.CreatedAt.In(location)
+	}
+
+	return RenderEmail(recipient.Locale, models.TemplateDigest, &EmailData{
+		Recipient: recipient,
+		Items:     items,
+		Data:      map[string]string{"frequency": string(settings.Digest)},
+	})
+}
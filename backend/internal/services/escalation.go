@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EscalationPolicy configures how long approvers have to decide on a request
+type EscalationPolicy struct {
+	// SLADays is the number of business days before a step is escalated
+	SLADays int
+	// ReminderDays are the business-day thresholds at which the approver is reminded
+	ReminderDays []int
+	// AutoApproveDaysBeforeStart auto-approves requests still pending this many
+	// days before they start; zero disables auto-approval
+	AutoApproveDaysBeforeStart int
+}
+
+// ApprovalEscalator reminds approvers of stale pending requests, escalates them
+// to the next level of the hierarchy and optionally auto-approves them
+type ApprovalEscalator struct {
+	db       *gorm.DB
+	policy   EscalationPolicy
+	interval time.Duration
+}
+
+func NewApprovalEscalator(db *gorm.DB, policy EscalationPolicy, interval time.Duration) *ApprovalEscalator {
+	return &ApprovalEscalator{
+		db:       db,
+		policy:   policy,
+		interval: interval,
+	}
+}
+
+// Start runs the escalator periodically until the context is cancelled
+func (e *ApprovalEscalator) Start(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Run(); err != nil {
+			log.Printf("Approval escalation failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run processes every pending request once
+func (e *ApprovalEscalator) Run() error {
+	var requests []models.VacationRequest
+	if err := e.db.Preload("User").Preload("ApprovalSteps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Where("status = ?", models.StatusPending).Find(&requests).Error; err != nil {
+		return fmt.Errorf("failed to fetch pending requests: %w", err)
+	}
+
+	now := time.Now()
+	for i := range requests {
+		if err := e.process(&requests[i], now); err != nil {
+			log.Printf("Failed to escalate request %s: %v", requests[i].ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *ApprovalEscalator) process(request *models.VacationRequest, now time.Time) error {
+	step := CurrentApprovalStep(request.ApprovalSteps)
+	if step == nil {
+		return nil
+	}
+
+	if e.policy.AutoApproveDaysBeforeStart > 0 &&
+		!now.AddDate(0, 0, e.policy.AutoApproveDaysBeforeStart).Before(request.StartDate) {
+		return e.autoApprove(request)
+	}
+
+	activatedAt := step.CreatedAt
+	if step.ActivatedAt != nil {
+		activatedAt = *step.ActivatedAt
+	}
+	elapsed := businessDaysBetween(activatedAt, now)
+
+	if e.policy.SLADays > 0 && elapsed >= e.policy.SLADays {
+		return e.escalate(request, step, now)
+	}
+
+	if step.RemindersSent < len(e.policy.ReminderDays) && elapsed >= e.policy.ReminderDays[step.RemindersSent] {
+		return e.remind(request, step, elapsed)
+	}
+
+	return nil
+}
+
+// autoApprove approves a request whose deadline has run out; the requester
+// and the approvers hear about it through the outbox
+func (e *ApprovalEscalator) autoApprove(request *models.VacationRequest) error {
+	_, err := DecideVacationRequest(e.db, DecisionInput{
+		RequestID: request.ID,
+		Approve:   true,
+		Comment:   "Aprovada automaticamente: prazo de aprovação esgotado antes do início das férias",
+		Source:    models.DecisionSystem,
+	})
+	if err == ErrRequestNotPending {
+		return nil
+	}
+	return err
+}
+
+// escalate hands the step over to the approver's manager, or to HR when there
+// is nobody above. Role-based steps are already at the top of the hierarchy:
+// they keep their role, the SLA starts over and the role is reminded again.
+func (e *ApprovalEscalator) escalate(request *models.VacationRequest, seen *models.ApprovalStep, now time.Time) error {
+	// Everyone involved hears about it through the outbox
+	return e.db.Transaction(func(tx *gorm.DB) error {
+		// Wait for a decision being recorded right now and leave it alone
+		if err := tx.Clauses(lockForUpdate).Select("id").Where("id = ?", request.ID).
+			First(&models.VacationRequest{}).Error; err != nil {
+			return err
+		}
+
+		// Another run may have decided or escalated the step since it was read
+		var step models.ApprovalStep
+		if err := tx.Clauses(lockForUpdate).Where("id = ?", seen.ID).First(&step).Error; err != nil {
+			return err
+		}
+		if step.Status != models.StepPending || !sameEscalation(&step, seen) {
+			return nil
+		}
+
+		previousID := step.ApproverID
+		step.EscalatedAt = &now
+		step.ActivatedAt = &now
+		step.RemindersSent = 0
+
+		if previousID != nil {
+			var approver models.User
+			if err := tx.Where("id = ?", *previousID).First(&approver).Error; err != nil && err != gorm.ErrRecordNotFound {
+				return err
+			}
+
+			previous := *previousID
+			step.EscalatedFromID = &previous
+			if approver.ManagerID != nil && *approver.ManagerID != request.UserID {
+				step.ApproverID = approver.ManagerID
+				step.ApproverRole = ""
+			} else {
+				step.ApproverID = nil
+				step.ApproverRole = models.RoleHR
+			}
+		}
+
+		if err := tx.Omit("Approver").Save(&step).Error; err != nil {
+			return err
+		}
+
+		entry := models.RequestHistory{
+			VacationRequestID: request.ID,
+			Action:            models.HistoryEscalated,
+			FromStatus:        request.Status,
+			ToStatus:          request.Status,
+			Comment:           fmt.Sprintf("Etapa %d escalada após %d dias úteis sem decisão", step.Position, e.policy.SLADays),
+		}
+		if previousID == nil {
+			entry.Comment = fmt.Sprintf("Etapa %d sem decisão após %d dias úteis; aprovadores (%s) lembrados novamente",
+				step.Position, e.policy.SLADays, step.ApproverRole)
+		} else {
+			escalatedTo := interface{}(string(step.ApproverRole))
+			if step.ApproverID != nil {
+				escalatedTo = step.ApproverID.String()
+			}
+			if err := entry.SetChanges(map[string]models.FieldChange{
+				"approver": {From: previousID.String(), To: escalatedTo},
+			}); err != nil {
+				return err
+			}
+		}
+		return RecordHistory(tx, entry, nil, nil)
+	})
+}
+
+// sameEscalation reports whether the step was not escalated since it was seen
+func sameEscalation(step, seen *models.ApprovalStep) bool {
+	if step.EscalatedAt == nil || seen.EscalatedAt == nil {
+		return step.EscalatedAt == nil && seen.EscalatedAt == nil
+	}
+	return step.EscalatedAt.Equal(*seen.EscalatedAt)
+}
+
+func (e *ApprovalEscalator) remind(request *models.VacationRequest, step *models.ApprovalStep, elapsed int) error {
+	// Only the run that records the reminder sends it; an escalation in the
+	// meantime starts the count over for the new approver
+	query := e.db.Model(&models.ApprovalStep{}).
+		Where("id = ? AND status = ? AND reminders_sent = ?", step.ID, models.StepPending, step.RemindersSent)
+	if step.EscalatedAt == nil {
+		query = query.Where("escalated_at IS NULL")
+	} else {
+		query = query.Where("escalated_at = ?", *step.EscalatedAt)
+	}
+	result := query.Update("reminders_sent", step.RemindersSent+1)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	step.RemindersSent++
+
+	message := fmt.Sprintf("A solicitação de férias de %s (%s a %s) aguarda sua decisão há %d dia(s) útil(eis).",
+		request.User.Name,
+		request.StartDate.Format("02/01/2006"),
+		request.EndDate.Format("02/01/2006"),
+		elapsed)
+	for _, recipient := range approverRecipients(e.db, step) {
+		if err := Deliver(e.db, approverNotice(e.db, request, step, recipient, models.NotificationReminder,
+			models.TemplateApprovalReminder, "Aprovação Pendente", message)); err != nil {
+			log.Printf("Failed to send approval reminder for request %s: %v", request.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// notifyEscalation tells whoever now decides the step that it was escalated
+// to them, or that it is still waiting for role-based steps, and the previous
+// approver that it was taken away from them
+func notifyEscalation(db *gorm.DB, request *models.VacationRequest, step *models.ApprovalStep, previousID *uuid.UUID) {
+	message := fmt.Sprintf("A solicitação de férias de %s (%s a %s) foi escalada para você por exceder o prazo de aprovação.",
+		request.User.Name,
+		request.StartDate.Format("02/01/2006"),
+		request.EndDate.Format("02/01/2006"))
+	if previousID == nil {
+		message = fmt.Sprintf("A solicitação de férias de %s (%s a %s) excedeu o prazo de aprovação e ainda aguarda decisão.",
+			request.User.Name,
+			request.StartDate.Format("02/01/2006"),
+			request.EndDate.Format("02/01/2006"))
+	}
+	for _, recipient := range approverRecipients(db, step) {
+		if err := Deliver(db, approverNotice(db, request, step, recipient, models.NotificationRequest,
+			models.TemplateApprovalExpired, "Solicitação Escalada", message)); err != nil {
+			log.Printf("Failed to notify escalation for request %s: %v", request.ID, err)
+		}
+	}
+
+	if previousID != nil {
+		notifyUsers(db, []uuid.UUID{*previousID}, models.NotificationSystem, "Solicitação Escalada",
+			fmt.Sprintf("A solicitação de férias de %s foi escalada por falta de resposta.", request.User.Name))
+	}
+}
+
+// businessDaysBetween counts the weekdays elapsed after from up to and including to
+func businessDaysBetween(from, to time.Time) int {
+	days := 0
+	for d := from.AddDate(0, 0, 1); !d.After(to); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidFeedCursor = errors.New("invalid feed cursor")
+
+// feedHorizonDays is how far ahead the feed announces leaves, returns and holidays
+const feedHorizonDays = 14
+
+// FeedCursor is the position of the last item of a feed page. Items are
+// ordered newest first by date, then by ID to break ties.
+type FeedCursor struct {
+	At time.Time
+	ID string
+}
+
+func (c *FeedCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.At.Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+// ParseFeedCursor reads a cursor returned by Encode
+func ParseFeedCursor(value string) (*FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidFeedCursor
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidFeedCursor
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil, ErrInvalidFeedCursor
+	}
+	return &FeedCursor{At: parsed, ID: id}, nil
+}
+
+// precedes reports whether the item comes after the cursor in the feed
+func (c *FeedCursor) precedes(item *models.FeedItem) bool {
+	return item.At.Before(c.At) || (item.At.Equal(c.At) && item.ID < c.ID)
+}
+
+// feedTeam selects the ids of the viewer's team: the viewer, their manager,
+// the colleagues sharing that manager and their direct reports, or everyone
+// below them when subtree is set
+func feedTeam(db *gorm.DB, viewer *models.User, subtree bool) *gorm.DB {
+	members := db.Where("id = ? OR manager_id = ?", viewer.ID, viewer.ID)
+	if viewer.ManagerID != nil {
+		members = members.Or("id = ? OR manager_id = ?", *viewer.ManagerID, *viewer.ManagerID)
+	}
+	if subtree {
+		members = members.Or("id IN (?)", SubtreeQuery(db, viewer.ID))
+	}
+	return db.Model(&models.User{}).Select("id").Where("active = ?", true).Where(members)
+}
+
+// TeamFeed returns a page of the events of the viewer's team, newest first,
+// and the cursor of the next page when there is one. Upcoming leaves, returns
+// and holidays within the horizon come first, dated on the day they happen.
+func TeamFeed(db *gorm.DB, viewer *models.User, subtree bool, cursor *FeedCursor, limit int) ([]models.FeedItem, *FeedCursor, error) {
+	now := time.Now()
+	today := startOfDay(now)
+	team := feedTeam(db.Session(&gorm.Session{NewDB: true}), viewer, subtree)
+
+	upcoming, err := upcomingFeedItems(db, team, today)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]models.FeedItem, 0, len(upcoming)+limit+1)
+	for i := range upcoming {
+		if cursor == nil || cursor.precedes(&upcoming[i]) {
+			items = append(items, upcoming[i])
+		}
+	}
+
+	// One extra entry tells whether the history goes on after this page
+	history, err := historyFeedItems(db, team, cursor, limit+1, today)
+	if err != nil {
+		return nil, nil, err
+	}
+	items = append(items, history...)
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].At.Equal(items[j].At) {
+			return items[i].At.After(items[j].At)
+		}
+		return items[i].ID > items[j].ID
+	})
+	if len(items) <= limit {
+		return items, nil, nil
+	}
+	items = items[:limit]
+	last := items[limit-1]
+	return items, &FeedCursor{At: last.At, ID: last.ID}, nil
+}
+
+// historyFeedItems builds the items of the team's request history after the cursor
+func historyFeedItems(db *gorm.DB, team *gorm.DB, cursor *FeedCursor, limit int, today time.Time) ([]models.FeedItem, error) {
+	actions := make([]models.HistoryAction, 0, len(models.FeedHistoryKinds))
+	for action := range models.FeedHistoryKinds {
+		actions = append(actions, action)
+	}
+
+	// IDs compare bytewise like the cursor does in Go
+	query := db.Model(&models.RequestHistory{}).
+		Joins("JOIN vacation_requests ON vacation_requests.id = request_history.vacation_request_id AND vacation_requests.deleted_at IS NULL").
+		Where("vacation_requests.user_id IN (?) AND request_history.action IN ?", team, actions)
+	if cursor != nil {
+		query = query.Where("request_history.created_at < ? OR (request_history.created_at = ? AND request_history.id::text COLLATE \"C\" < ?)",
+			cursor.At, cursor.At, cursor.ID)
+	}
+
+	var entries []models.RequestHistory
+	if err := query.Order("request_history.created_at DESC, request_history.id::text COLLATE \"C\" DESC").
+		Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch team history: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	requestIDs := make([]uuid.UUID, 0, len(entries))
+	for _, entry := range entries {
+		requestIDs = append(requestIDs, entry.VacationRequestID)
+	}
+	var requests []models.VacationRequest
+	if err := db.Preload("User").Where("id IN ?", requestIDs).Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch team requests: %w", err)
+	}
+	byID := make(map[uuid.UUID]*models.VacationRequest, len(requests))
+	for i := range requests {
+		byID[requests[i].ID] = &requests[i]
+	}
+
+	items := make([]models.FeedItem, 0, len(entries))
+	for _, entry := range entries {
+		request, ok := byID[entry.VacationRequestID]
+		if !ok {
+			continue
+		}
+		kind := models.FeedHistoryKinds[entry.Action]
+		item := feedItem(entry.ID.String(), kind, entry.CreatedAt, request)
+		item.Message = historyFeedMessage(kind, request, today)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// upcomingFeedItems builds the leaves starting, the returns and the holidays
+// from tomorrow until the horizon. Holidays of today are announced as well.
+func upcomingFeedItems(db *gorm.DB, team *gorm.DB, today time.Time) ([]models.FeedItem, error) {
+	tomorrow := today.AddDate(0, 0, 1)
+	horizon := today.AddDate(0, 0, feedHorizonDays)
+
+	// A return falls at most a weekend after the end date
+	var requests []models.VacationRequest
+	if err := db.Preload("User").
+		Where("user_id IN (?) AND status IN ? AND start_date <= ? AND end_date >= ?",
+			team, models.OnLeaveStatuses, horizon, today.AddDate(0, 0, -3)).
+		Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch upcoming team leaves: %w", err)
+	}
+
+	var items []models.FeedItem
+	for i := range requests {
+		request := &requests[i]
+		if start := startOfDay(request.StartDate); request.Status == models.StatusApproved && !start.Before(tomorrow) {
+			item := feedItem("leave:"+request.ID.String(), models.FeedUpcomingLeave, start, request)
+			item.Message = fmt.Sprintf("%s sai de férias %s (%s a %s).", request.User.Name, feedDay(start, today),
+				request.StartDate.Format("02/01/2006"), request.EndDate.Format("02/01/2006"))
+			items = append(items, item)
+		}
+		if back := returnDate(request); !back.Before(tomorrow) && !back.After(horizon) {
+			item := feedItem("return:"+request.ID.String(), models.FeedUpcomingReturn, back, request)
+			item.Message = fmt.Sprintf("%s volta de férias %s.", request.User.Name, feedDay(back, today))
+			items = append(items, item)
+		}
+	}
+
+	var holidays []models.Holiday
+	if err := db.Where("date >= ? AND date <= ?", today, horizon).Find(&holidays).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch holidays: %w", err)
+	}
+	for _, holiday := range holidays {
+		day := startOfDay(holiday.Date)
+		items = append(items, models.FeedItem{
+			ID:      "holiday:" + holiday.ID.String(),
+			Kind:    models.FeedHoliday,
+			At:      day,
+			Message: fmt.Sprintf("Feriado %s: %s.", feedDay(day, today), holiday.Name),
+		})
+	}
+	return items, nil
+}
+
+// feedItem fills the parts of an item about a request. Only the requester's
+// name, department and period are exposed.
+func feedItem(id string, kind models.FeedItemKind, at time.Time, request *models.VacationRequest) models.FeedItem {
+	startDate, endDate := request.StartDate, request.EndDate
+	return models.FeedItem{
+		ID:   id,
+		Kind: kind,
+		At:   at,
+		User: &models.FeedUser{
+			ID:         request.User.ID.String(),
+			Name:       request.User.Name,
+			Department: request.User.Department,
+		},
+		StartDate: &startDate,
+		EndDate:   &endDate,
+	}
+}
+
+func historyFeedMessage(kind models.FeedItemKind, request *models.VacationRequest, today time.Time) string {
+	name := request.User.Name
+	start := request.StartDate.Format("02/01/2006")
+	end := request.EndDate.Format("02/01/2006")
+
+	switch kind {
+	case models.FeedLeaveApproved:
+		return fmt.Sprintf("Férias de %s aprovadas: %s a %s.", name, start, end)
+	case models.FeedApprovalRevoked:
+		return fmt.Sprintf("A aprovação das férias de %s (%s a %s) foi revogada.", name, start, end)
+	case models.FeedLeaveStarted:
+		return fmt.Sprintf("%s saiu de férias; retorno %s.", name, feedDay(returnDate(request), today))
+	case models.FeedLeaveCompleted:
+		return fmt.Sprintf("%s voltou de férias.", name)
+	case models.FeedLeaveInterrupted:
+		return fmt.Sprintf("As férias de %s foram interrompidas.", name)
+	}
+	return ""
+}
+
+// feedDay describes a day relative to today: "hoje", "amanhã" or the date
+func feedDay(day, today time.Time) string {
+	switch days := math.Round(startOfDay(day).Sub(today).Hours() / 24); days {
+	case 0:
+		return "hoje"
+	case 1:
+		return "amanhã"
+	}
+	return "em " + day.Format("02/01/2006")
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HandoverReminder notifies requesters whose handover is still empty
+// a few days before their vacation starts
+type HandoverReminder struct {
+	db         *gorm.DB
+	daysBefore int
+	interval   time.Duration
+}
+
+func NewHandoverReminder(db *gorm.DB, daysBefore int, interval time.Duration) *HandoverReminder {
+	return &HandoverReminder{
+		db:         db,
+		daysBefore: daysBefore,
+		interval:   interval,
+	}
+}
+
+// Start runs the reminder periodically until the context is cancelled
+func (r *HandoverReminder) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Run(); err != nil {
+			log.Printf("Handover reminder failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run sends the reminders that are due, at most once per request
+func (r *HandoverReminder) Run() error {
+	now := time.Now()
+	limit := now.AddDate(0, 0, r.daysBefore)
+
+	var requests []models.VacationRequest
+	if err := r.db.Where("status IN (?, ?) AND start_date > ? AND start_date <= ?",
+		models.StatusPending, models.StatusApproved, now, limit).
+		Find(&requests).Error; err != nil {
+		return fmt.Errorf("failed to fetch upcoming requests: %w", err)
+	}
+
+	for _, request := range requests {
+		if err := r.remind(request); err != nil {
+			log.Printf("Failed to send handover reminder for request %s: %v", request.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *HandoverReminder) remind(request models.VacationRequest) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Requests whose handover was never opened get an empty one to claim
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.Handover{VacationRequestID: request.ID}).Error; err != nil {
+			return err
+		}
+
+		var handover models.Handover
+		if err := tx.Preload("Items").Where("vacation_request_id = ?", request.ID).First(&handover).Error; err != nil {
+			return err
+		}
+		if !handover.IsEmpty() || handover.ReminderSentAt != nil {
+			return nil
+		}
+
+		// Only the run that claims the reminder sends it
+		result := tx.Model(&models.Handover{}).
+			Where("id = ? AND reminder_sent_at IS NULL", handover.ID).
+			Update("reminder_sent_at", time.Now())
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+
+		message := fmt.Sprintf("Suas férias começam em %s e a passagem de bastão ainda está vazia. Registre tarefas abertas, contatos e responsáveis.",
+			request.StartDate.Format("02/01/2006"))
+		return Notify(tx, request.UserID, models.NotificationReminder, "Passagem de Bastão Pendente", message)
+	})
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// subtreeSQL walks manager_id downwards from a manager. UNION rather than
+// UNION ALL stops the walk if the data ever contains a reporting cycle.
+const subtreeSQL = `WITH RECURSIVE subtree AS (
+	SELECT id FROM users WHERE manager_id = ? AND deleted_at IS NULL
+	UNION
+	SELECT u.id FROM users u JOIN subtree s ON u.manager_id = s.id WHERE u.deleted_at IS NULL
+) SELECT id FROM subtree`
+
+// SubtreeQuery selects the ids of everyone reporting to the manager, directly
+// or through other managers. It is meant to be used as a subquery.
+func SubtreeQuery(db *gorm.DB, managerID uuid.UUID) *gorm.DB {
+	return db.Raw(subtreeSQL, managerID)
+}
+
+// Subordinates returns the ids of everyone below the manager in the hierarchy
+func Subordinates(db *gorm.DB, managerID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := SubtreeQuery(db, managerID).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// IsInSubtree reports whether the user reports to the manager at any depth
+func IsInSubtree(db *gorm.DB, managerID, userID uuid.UUID) (bool, error) {
+	var count int64
+	if err := db.Raw("SELECT COUNT(*) FROM ("+subtreeSQL+") t WHERE t.id = ?", managerID, userID).
+		Scan(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
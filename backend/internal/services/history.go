@@ -0,0 +1,49 @@
+package services
+
+import (
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecordHistory appends an entry to the request timeline. When both versions
+// of the request are given, the changed fields are stored with the entry.
+// Callers should pass the transaction that changed the request so the entry
+// is only kept when the change is. Status changes are also pushed to the
+// real-time stream, and every entry is written to the outbox for the
+// notification and webhook consumers.
+func RecordHistory(db *gorm.DB, entry models.RequestHistory, before, after *models.VacationRequest) error {
+	return recordHistory(db, &entry, before, after, models.RequestEventPayload{})
+}
+
+// RecordCommentHistory is RecordHistory for a new comment; its outbox event
+// points at the comment so the thread can be told about it
+func RecordCommentHistory(db *gorm.DB, entry models.RequestHistory, commentID uuid.UUID) error {
+	return recordHistory(db, &entry, nil, nil, models.RequestEventPayload{CommentID: commentID.String()})
+}
+
+func recordHistory(db *gorm.DB, entry *models.RequestHistory, before, after *models.VacationRequest, payload models.RequestEventPayload) error {
+	if before != nil && after != nil {
+		if err := entry.SetChanges(models.TrackedChanges(before, after)); err != nil {
+			return err
+		}
+		if entry.FromStatus == "" {
+			entry.FromStatus = before.Status
+		}
+		if entry.ToStatus == "" {
+			entry.ToStatus = after.Status
+		}
+	}
+	if err := db.Create(entry).Error; err != nil {
+		return err
+	}
+
+	if entry.ToStatus != "" && entry.ToStatus != entry.FromStatus {
+		if err := publishRequestStatus(db, entry); err != nil {
+			return err
+		}
+	}
+	payload.HistoryID = entry.ID.String()
+	return RecordEvent(db, models.AggregateVacationRequest, entry.VacationRequestID,
+		models.RequestEventType(entry.Action), payload)
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotAllowedToInterrupt = errors.New("user cannot interrupt this vacation request")
+	ErrRequestChanged        = errors.New("vacation request was changed by someone else")
+	ErrInsufficientBalance   = errors.New("insufficient vacation balance")
+)
+
+var (
+	// lockForUpdate makes the loaded rows stay locked until the transaction
+	// ends, so concurrent changes to the same request run one after the other
+	lockForUpdate = clause.Locking{Strength: "UPDATE"}
+	// lockSkipLocked lets concurrent workers claim different rows of a queue
+	lockSkipLocked = clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}
+)
+
+// ApplyBalance adds delta business days to the user's vacation balance. The
+// balance is checked in the same statement that debits it, so two approvals
+// racing for the last days cannot both succeed. Subscribers of user.updated
+// are sent the new balance.
+func ApplyBalance(db *gorm.DB, userID uuid.UUID, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+
+	query := db.Model(&models.User{}).Where("id = ?", userID)
+	if delta < 0 {
+		query = query.Where("vacation_balance >= ?", -delta)
+	}
+	result := query.Update("vacation_balance", gorm.Expr("vacation_balance + ?", delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientBalance
+	}
+	return PublishUserUpdated(db, userID)
+}
+
+// SaveVacationRequest writes every column of the request, provided nobody
+// else wrote it since it was loaded. Otherwise nothing is written and
+// ErrRequestChanged is returned.
+func SaveVacationRequest(db *gorm.DB, request *models.VacationRequest) error {
+	result := db.Select("*").Omit(clause.Associations, "CreatedAt").
+		Where("version = ?", request.Version).
+		Updates(request)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRequestChanged
+	}
+	return nil
+}
+
+// InterruptLeave ends a running leave before its planned end. The requester,
+// their manager, HR and admins may record an interruption.
+func InterruptLeave(db *gorm.DB, requestID, actorID uuid.UUID, actorRole models.UserRole, returnDate time.Time, comment string) (*models.VacationRequest, error) {
+	var request models.VacationRequest
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(lockForUpdate).Preload("User").Where("id = ?", requestID).First(&request).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrRequestNotFound
+			}
+			return err
+		}
+
+		isManager := request.User.ManagerID != nil && *request.User.ManagerID == actorID
+		if request.UserID != actorID && !isManager && actorRole != models.RoleAdmin && actorRole != models.RoleHR {
+			return ErrNotAllowedToInterrupt
+		}
+
+		previous := request
+		effect, err := request.Interrupt(returnDate, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := SaveVacationRequest(tx, &request); err != nil {
+			return err
+		}
+		if err := RecordHistory(tx, models.RequestHistory{
+			VacationRequestID: request.ID,
+			ActorID:           &actorID,
+			Action:            models.HistoryInterrupted,
+			Comment:           comment,
+		}, &previous, &request); err != nil {
+			return err
+		}
+		return ApplyBalance(tx, request.UserID, effect.BalanceDelta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// LeaveLifecycle moves approved requests to in progress when the leave starts
+// and to completed once it has ended
+type LeaveLifecycle struct {
+	db       *gorm.DB
+	interval time.Duration
+}
+
+func NewLeaveLifecycle(db *gorm.DB, interval time.Duration) *LeaveLifecycle {
+	return &LeaveLifecycle{
+		db:       db,
+		interval: interval,
+	}
+}
+
+// Start runs the transitions periodically until the context is cancelled
+func (l *LeaveLifecycle) Start(ctx context.Context) {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := l.Run(); err != nil {
+			log.Printf("Leave lifecycle update failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run applies the date-based transitions that are due
+func (l *LeaveLifecycle) Run() error {
+	now := time.Now()
+
+	var requests []models.VacationRequest
+	if err := l.db.Where("(status = ? AND start_date <= ?) OR (status = ? AND end_date < ?)",
+		models.StatusApproved, now, models.StatusInProgress, startOfDay(now)).
+		Find(&requests).Error; err != nil {
+		return fmt.Errorf("failed to fetch requests to update: %w", err)
+	}
+
+	for i := range requests {
+		if err := l.advance(&requests[i], now); err != nil {
+			log.Printf("Failed to update status of request %s: %v", requests[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// advance applies every transition due for the request; a leave that was
+// missed entirely goes through in progress before completing. The manager
+// and the substitute hear about each transition through the outbox.
+func (l *LeaveLifecycle) advance(request *models.VacationRequest, now time.Time) error {
+	return l.db.Transaction(func(tx *gorm.DB) error {
+		// The request may have been interrupted since it was listed
+		if err := tx.Clauses(lockForUpdate).Preload("User").Where("id = ?", request.ID).First(request).Error; err != nil {
+			return err
+		}
+
+		for _, event := range []models.VacationEvent{models.EventStart, models.EventComplete} {
+			if request.Can(event, now) != nil {
+				continue
+			}
+
+			previous := *request
+			effect, err := request.Apply(event, now)
+			if err != nil {
+				return err
+			}
+			if err := SaveVacationRequest(tx, request); err != nil {
+				return err
+			}
+
+			action := models.HistoryStarted
+			if event == models.EventComplete {
+				action = models.HistoryCompleted
+			}
+			if err := RecordHistory(tx, models.RequestHistory{
+				VacationRequestID: request.ID,
+				Action:            action,
+			}, &previous, request); err != nil {
+				return err
+			}
+			if err := ApplyBalance(tx, request.UserID, effect.BalanceDelta); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notify stores an in-app notification for the given user and queues its
+// delivery on the other channels configured for it
+func Notify(db *gorm.DB, userID uuid.UUID, notificationType models.NotificationType, title, message string) error {
+	return Deliver(db, &models.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		Title:   title,
+		Message: message,
+	})
+}
+
+// Deliver stores a prepared notification, such as one rendered from a
+// template, pushes it to the recipient's real-time stream and queues its
+// delivery like Notify
+func Deliver(db *gorm.DB, notification *models.Notification) error {
+	if err := db.Create(notification).Error; err != nil {
+		return err
+	}
+
+	if err := PublishStreamEvent(db, notification.UserID, models.StreamNotification, notification.ToResponse()); err != nil {
+		return err
+	}
+
+	if dispatcher == nil {
+		return nil
+	}
+	return dispatcher.Enqueue(db, notification)
+}
+
+// requestNotice builds a notification about a request rendered with one of
+// the lifecycle templates
+func requestNotice(userID uuid.UUID, request *models.VacationRequest, notificationType models.NotificationType, template, title, message string, data map[string]string) *models.Notification {
+	notification := &models.Notification{
+		UserID:            userID,
+		Type:              notificationType,
+		Title:             title,
+		Message:           message,
+		VacationRequestID: &request.ID,
+		Template:          template,
+	}
+	_ = notification.SetData(data)
+	return notification
+}
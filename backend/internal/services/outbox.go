@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxConsumer applies domain events to one destination, such as the
+// notification center or webhook subscribers. Handle runs in a transaction
+// that also records the event as processed, so everything it writes with tx
+// happens exactly once per event.
+type OutboxConsumer interface {
+	Name() string
+	Handle(tx *gorm.DB, event *models.OutboxEvent) error
+}
+
+// RecordEvent writes a domain event to the outbox. Pass the transaction that
+// made the change so the event exists if, and only if, the change commits.
+func RecordEvent(db *gorm.DB, aggregate string, aggregateID uuid.UUID, eventType string, payload interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event := models.OutboxEvent{
+		Aggregate:     aggregate,
+		AggregateID:   aggregateID,
+		Type:          eventType,
+		Payload:       string(encoded),
+		Status:        models.OutboxPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := db.Create(&event).Error; err != nil {
+		return fmt.Errorf("failed to record outbox event: %w", err)
+	}
+	return nil
+}
+
+type OutboxRelayConfig struct {
+	// MaxAttempts is how many times an event is relayed before it is failed
+	MaxAttempts int
+	// RetryDelay is the wait after the first failure, doubled on each retry
+	RetryDelay time.Duration
+	BatchSize  int
+	// Retention is how long published events are kept for the metrics
+	Retention time.Duration
+}
+
+// OutboxRelay hands outbox events to the consumers that have not processed
+// them yet. Several instances may run it at once.
+type OutboxRelay struct {
+	db        *gorm.DB
+	config    OutboxRelayConfig
+	consumers []OutboxConsumer
+	interval  time.Duration
+}
+
+func NewOutboxRelay(db *gorm.DB, config OutboxRelayConfig, interval time.Duration, consumers ...OutboxConsumer) *OutboxRelay {
+	return &OutboxRelay{
+		db:        db,
+		config:    config,
+		consumers: consumers,
+		interval:  interval,
+	}
+}
+
+var outboxRelay *OutboxRelay
+
+// SetOutboxRelay makes the relay's consumers known to OutboxMetrics
+func SetOutboxRelay(r *OutboxRelay) {
+	outboxRelay = r
+}
+
+// Start relays due events periodically until the context is cancelled
+func (r *OutboxRelay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	var lastPrune time.Time
+	for {
+		if err := r.Run(); err != nil {
+			log.Printf("Outbox relay failed: %v", err)
+		}
+		if time.Since(lastPrune) >= time.Hour {
+			if err := r.prune(); err != nil {
+				log.Printf("Failed to prune outbox: %v", err)
+			}
+			lastPrune = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run relays every event that is due
+func (r *OutboxRelay) Run() error {
+	for {
+		events, err := r.claim()
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		for i := range events {
+			r.relay(&events[i])
+		}
+	}
+}
+
+// claim picks a batch of due events, oldest first, and pushes their next
+// attempt out so other instances skip them while they are relayed
+func (r *OutboxRelay) claim() ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(lockSkipLocked).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, time.Now()).
+			Order("id ASC").
+			Limit(r.config.BatchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(5*time.Minute)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+// relay hands the event to each consumer and records the outcome. Consumers
+// that succeed are not called again when the others are retried.
+func (r *OutboxRelay) relay(event *models.OutboxEvent) {
+	var errs []error
+	for _, consumer := range r.consumers {
+		if err := r.consume(consumer, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", consumer.Name(), err))
+		}
+	}
+	relayErr := errors.Join(errs...)
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts": event.Attempts + 1,
+	}
+	switch {
+	case relayErr == nil:
+		updates["status"] = models.OutboxPublished
+		updates["published_at"] = now
+		updates["last_error"] = ""
+	case event.Attempts+1 >= r.config.MaxAttempts:
+		updates["status"] = models.OutboxFailed
+		updates["last_error"] = relayErr.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(r.config.RetryDelay << event.Attempts)
+		updates["last_error"] = relayErr.Error()
+	}
+	if relayErr != nil {
+		log.Printf("Failed to relay outbox event %d (attempt %d): %v", event.ID, event.Attempts+1, relayErr)
+	}
+
+	if err := r.db.Model(event).Updates(updates).Error; err != nil {
+		log.Printf("Failed to record outbox event %d: %v", event.ID, err)
+	}
+}
+
+// consume runs one consumer on the event unless it already processed it. Any
+// failed statement aborts the transaction, so a consumer that could not write
+// everything is retried instead of being marked done.
+func (r *OutboxRelay) consume(consumer OutboxConsumer, event *models.OutboxEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.OutboxConsumption{
+			EventID:     event.ID,
+			Consumer:    consumer.Name(),
+			ProcessedAt: now,
+			LagMs:       now.Sub(event.CreatedAt).Milliseconds(),
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to record consumption: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return consumer.Handle(tx, event)
+	})
+}
+
+// prune removes published events older than the retention, with their
+// consumption records
+func (r *OutboxRelay) prune() error {
+	cutoff := time.Now().Add(-r.config.Retention)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		published := tx.Model(&models.OutboxEvent{}).Select("id").
+			Where("status = ? AND published_at < ?", models.OutboxPublished, cutoff)
+		if err := tx.Where("event_id IN (?)", published).Delete(&models.OutboxConsumption{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("status = ? AND published_at < ?", models.OutboxPublished, cutoff).
+			Delete(&models.OutboxEvent{}).Error
+	})
+}
+
+// OutboxMetrics reports the relay's backlog and how far behind each consumer
+// is. Without a running relay only the backlog is reported.
+func OutboxMetrics(db *gorm.DB) (*models.OutboxMetricsResponse, error) {
+	metrics := &models.OutboxMetricsResponse{Consumers: []models.OutboxConsumerMetrics{}}
+
+	if err := db.Model(&models.OutboxEvent{}).Where("status = ?", models.OutboxPending).
+		Count(&metrics.Pending).Error; err != nil {
+		return nil, fmt.Errorf("failed to count pending outbox events: %w", err)
+	}
+	if err := db.Model(&models.OutboxEvent{}).Where("status = ?", models.OutboxFailed).
+		Count(&metrics.Failed).Error; err != nil {
+		return nil, fmt.Errorf("failed to count failed outbox events: %w", err)
+	}
+
+	var oldest models.OutboxEvent
+	err := db.Where("status = ?", models.OutboxPending).Order("id ASC").First(&oldest).Error
+	switch {
+	case err == nil:
+		metrics.OldestPendingAgeSeconds = time.Since(oldest.CreatedAt).Seconds()
+	case err != gorm.ErrRecordNotFound:
+		return nil, fmt.Errorf("failed to fetch oldest outbox event: %w", err)
+	}
+
+	if outboxRelay == nil {
+		return metrics, nil
+	}
+
+	since := time.Now().Add(-time.Hour)
+	for _, consumer := range outboxRelay.consumers {
+		consumerMetrics := models.OutboxConsumerMetrics{Name: consumer.Name()}
+
+		if err := db.Model(&models.OutboxEvent{}).
+			Where("status <> ?", models.OutboxPublished).
+			Where("NOT EXISTS (SELECT 1 FROM outbox_consumptions c WHERE c.event_id = outbox_events.id AND c.consumer = ?)", consumer.Name()).
+			Count(&consumerMetrics.Pending).Error; err != nil {
+			return nil, fmt.Errorf("failed to count pending events of %s: %w", consumer.Name(), err)
+		}
+
+		var summary struct {
+			Processed       int64
+			AvgLagMs        float64
+			MaxLagMs        int64
+			LastProcessedAt *time.Time
+		}
+		if err := db.Model(&models.OutboxConsumption{}).
+			Select("COUNT(*) AS processed, COALESCE(AVG(lag_ms), 0) AS avg_lag_ms, COALESCE(MAX(lag_ms), 0) AS max_lag_ms, MAX(processed_at) AS last_processed_at").
+			Where("consumer = ? AND processed_at > ?", consumer.Name(), since).
+			Scan(&summary).Error; err != nil {
+			return nil, fmt.Errorf("failed to summarize events of %s: %w", consumer.Name(), err)
+		}
+		consumerMetrics.Processed = summary.Processed
+		consumerMetrics.AvgLagMs = summary.AvgLagMs
+		consumerMetrics.MaxLagMs = summary.MaxLagMs
+		consumerMetrics.LastProcessedAt = summary.LastProcessedAt
+
+		metrics.Consumers = append(metrics.Consumers, consumerMetrics)
+	}
+
+	return metrics, nil
+}
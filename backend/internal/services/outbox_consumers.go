@@ -0,0 +1,263 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// loadEventHistory returns the history entry a vacation request event mirrors
+func loadEventHistory(db *gorm.DB, event *models.OutboxEvent) (*models.RequestHistory, error) {
+	var payload models.RequestEventPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return nil, fmt.Errorf("invalid event payload: %w", err)
+	}
+
+	var entry models.RequestHistory
+	if err := db.Where("id = ?", payload.HistoryID).First(&entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to load history entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// NotificationConsumer notifies the people involved in a request of each
+// step of its life: submission, decisions, escalations, substitute answers,
+// comments, cancellation and the leave itself. Delegates hear about the
+// delegations they receive.
+type NotificationConsumer struct{}
+
+func NewNotificationConsumer() *NotificationConsumer {
+	return &NotificationConsumer{}
+}
+
+func (NotificationConsumer) Name() string {
+	return "notifications"
+}
+
+func (NotificationConsumer) Handle(tx *gorm.DB, event *models.OutboxEvent) error {
+	if event.Aggregate == models.AggregateDelegation && event.Type == models.EventDelegationCreated {
+		var delegation models.ApprovalDelegation
+		if err := tx.Preload("Delegator").Where("id = ?", event.AggregateID).First(&delegation).Error; err != nil {
+			return fmt.Errorf("failed to load delegation: %w", err)
+		}
+		notifyDelegation(tx, &delegation)
+		return nil
+	}
+	if event.Aggregate != models.AggregateVacationRequest {
+		return nil
+	}
+	entry, err := loadEventHistory(tx, event)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Action {
+	case models.HistoryCreated, models.HistorySubmitted, models.HistoryUpdated,
+		models.HistoryStepApproved, models.HistoryApproved, models.HistoryRejected,
+		models.HistoryAutoApproved, models.HistoryRevoked, models.HistoryCancelled,
+		models.HistoryStarted, models.HistoryCompleted, models.HistoryInterrupted,
+		models.HistoryEscalated, models.HistorySubstituteAccepted, models.HistorySubstituteDeclined,
+		models.HistoryCommented:
+	default:
+		return nil
+	}
+
+	var request models.VacationRequest
+	if err := tx.Preload("User").Preload("ApprovalSteps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Where("id = ?", entry.VacationRequestID).First(&request).Error; err != nil {
+		return fmt.Errorf("failed to load vacation request: %w", err)
+	}
+
+	switch entry.Action {
+	case models.HistoryCreated, models.HistorySubmitted:
+		// Drafts are announced once they are submitted
+		if entry.ToStatus != models.StatusPending {
+			return nil
+		}
+		// A team rule may have approved the request in the same transaction,
+		// and the first approver may have decided it already
+		if step := CurrentApprovalStep(request.ApprovalSteps); request.Status == models.StatusPending && step != nil && step.Position == 1 {
+			NotifyNextApprover(tx, &request, step)
+		}
+		if request.SubstituteID != nil && request.SubstituteStatus == models.SubstitutePending {
+			notifySubstituteRequest(tx, &request)
+		}
+
+	case models.HistoryUpdated:
+		if entry.ToStatus != models.StatusPending || request.Status != models.StatusPending {
+			return nil
+		}
+		var changes map[string]models.FieldChange
+		if err := json.Unmarshal([]byte(entry.Changes), &changes); err != nil {
+			return fmt.Errorf("invalid history changes: %w", err)
+		}
+		_, startChanged := changes["start_date"]
+		_, endChanged := changes["end_date"]
+		_, substituteChanged := changes["substitute_id"]
+		periodChanged := startChanged || endChanged
+
+		// A new substitute or a new period requires the coverage to be confirmed again
+		if (substituteChanged || periodChanged) && request.SubstituteID != nil && request.SubstituteStatus == models.SubstitutePending {
+			notifySubstituteRequest(tx, &request)
+		}
+		// The approver may be looking at the old period
+		if step := CurrentApprovalStep(request.ApprovalSteps); periodChanged && step != nil {
+			NotifyStepApprovers(tx, &request, step, "", "Solicitação Alterada",
+				fmt.Sprintf("A solicitação de férias de %s foi alterada e agora vai de %s a %s.",
+					request.User.Name,
+					request.StartDate.Format("02/01/2006"),
+					request.EndDate.Format("02/01/2006")))
+		}
+
+	case models.HistoryStepApproved:
+		// The step decided is the last one approved before the entry was
+		// written; later levels may have been decided since
+		var step, next *models.ApprovalStep
+		for i := range request.ApprovalSteps {
+			candidate := &request.ApprovalSteps[i]
+			if candidate.Status == models.StepApproved && candidate.DecidedAt != nil && !candidate.DecidedAt.After(entry.CreatedAt) {
+				step = candidate
+			}
+		}
+		if step == nil {
+			return nil
+		}
+		for i := range request.ApprovalSteps {
+			if request.ApprovalSteps[i].Position == step.Position+1 {
+				next = &request.ApprovalSteps[i]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+
+		if request.Status == models.StatusPending && next.Status == models.StepPending {
+			NotifyNextApprover(tx, &request, next)
+		}
+		notifyStepApproved(tx, &request, step, next)
+
+	case models.HistoryApproved, models.HistoryRejected, models.HistoryAutoApproved:
+		// Tell the decision as it was made, even if the request changed since
+		request.Status = entry.ToStatus
+		request.ApprovalComment = entry.Comment
+		if entry.Action != models.HistoryAutoApproved {
+			notifyDecision(tx, &request)
+			return nil
+		}
+
+		// Auto-approvals come from a team rule or from an expired deadline
+		if request.AutoApprovalRuleID == nil {
+			notifyExpiredApproval(tx, &request)
+			return nil
+		}
+		var rule models.AutoApprovalRule
+		if err := tx.Where("id = ?", *request.AutoApprovalRuleID).First(&rule).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				return fmt.Errorf("failed to load auto-approval rule: %w", err)
+			}
+			notifyRuleApproval(tx, &request, nil)
+			return nil
+		}
+		notifyRuleApproval(tx, &request, &rule)
+
+	case models.HistoryRevoked:
+		notifyRevocation(tx, &request, entry.Comment)
+
+	case models.HistoryCancelled:
+		// Drafts never reached the substitute or the approvers
+		if entry.FromStatus != models.StatusPending {
+			return nil
+		}
+		// The step awaiting a decision was the first one the cancellation skipped
+		var step *models.ApprovalStep
+		for i := range request.ApprovalSteps {
+			if request.ApprovalSteps[i].Status == models.StepSkipped {
+				step = &request.ApprovalSteps[i]
+				break
+			}
+		}
+		notifyCancellation(tx, &request, step)
+
+	case models.HistoryStarted:
+		notifyLeaveEvent(tx, &request, models.EventStart)
+
+	case models.HistoryCompleted:
+		notifyLeaveEvent(tx, &request, models.EventComplete)
+
+	case models.HistoryInterrupted:
+		notifyInterruption(tx, &request, entry.ActorID, entry.Comment)
+
+	case models.HistoryEscalated:
+		// Steps are escalated in place, so the escalated one is still the one
+		// awaiting a decision unless the request was decided since
+		step := CurrentApprovalStep(request.ApprovalSteps)
+		if request.Status != models.StatusPending || step == nil {
+			return nil
+		}
+		var changes map[string]models.FieldChange
+		if err := json.Unmarshal([]byte(entry.Changes), &changes); err != nil {
+			return fmt.Errorf("invalid history changes: %w", err)
+		}
+		var previousID *uuid.UUID
+		if from, ok := changes["approver"].From.(string); ok {
+			if id, err := uuid.Parse(from); err == nil {
+				previousID = &id
+			}
+		}
+		notifyEscalation(tx, &request, step, previousID)
+
+	case models.HistorySubstituteAccepted, models.HistorySubstituteDeclined:
+		notifySubstituteResponse(tx, &request, entry.ActorID, entry.Action == models.HistorySubstituteAccepted, entry.Comment)
+
+	case models.HistoryCommented:
+		var payload models.RequestEventPayload
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return fmt.Errorf("invalid event payload: %w", err)
+		}
+		// Comments recorded before their events carried them were notified directly
+		if payload.CommentID == "" {
+			return nil
+		}
+		var comment models.RequestComment
+		if err := tx.Preload("Author").Preload("Mentions").Where("id = ?", payload.CommentID).First(&comment).Error; err != nil {
+			return fmt.Errorf("failed to load comment: %w", err)
+		}
+		notifyComment(tx, &request, &comment)
+	}
+	return nil
+}
+
+// WebhookConsumer queues webhook deliveries for request lifecycle events and
+// user changes
+type WebhookConsumer struct{}
+
+func NewWebhookConsumer() *WebhookConsumer {
+	return &WebhookConsumer{}
+}
+
+func (WebhookConsumer) Name() string {
+	return "webhooks"
+}
+
+func (WebhookConsumer) Handle(tx *gorm.DB, event *models.OutboxEvent) error {
+	switch event.Aggregate {
+	case models.AggregateVacationRequest:
+		entry, err := loadEventHistory(tx, event)
+		if err != nil {
+			return err
+		}
+		return publishRequestEvent(tx, entry)
+
+	case models.AggregateUser:
+		var user models.User
+		if err := tx.Where("id = ?", event.AggregateID).First(&user).Error; err != nil {
+			return fmt.Errorf("failed to load user: %w", err)
+		}
+		return PublishWebhookEvent(tx, models.WebhookUserUpdated, user.ToWebhookData())
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// exclusionViolation is the PostgreSQL error code of a broken exclusion constraint
+const exclusionViolation = "23P01"
+
+// OverlappingRequests returns the user's reserved requests sharing at least
+// one day with the period
+func OverlappingRequests(db *gorm.DB, userID uuid.UUID, excludeID *uuid.UUID, startDate, endDate time.Time) ([]models.VacationRequest, error) {
+	query := db.Where("user_id = ? AND status IN ? AND start_date <= ? AND end_date >= ?",
+		userID, models.ReservedStatuses, endDate, startDate)
+	if excludeID != nil {
+		query = query.Where("id <> ?", *excludeID)
+	}
+
+	var requests []models.VacationRequest
+	if err := query.Order("start_date ASC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// IsOverlapViolation reports whether a write was refused by the database
+// because the request would overlap another reserved request of the user
+func IsOverlapViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == exclusionViolation &&
+		pgErr.ConstraintName == models.OverlapConstraint
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReminderPolicy configures the scheduled reminders; a zero value disables
+// the matching reminder
+type ReminderPolicy struct {
+	// UpcomingDays is how many days before the start requesters are reminded
+	UpcomingDays int
+	// NoticeDays is how far ahead of the start the vacation notice is due
+	NoticeDays int
+	// NoticeLeadDays is how early HR is warned before the notice is due
+	NoticeLeadDays int
+}
+
+// ReminderScheduler sends the date-based reminders: upcoming leaves, returns
+// from leave and vacation notices HR has to issue. Approvers are reminded by
+// the ApprovalEscalator and empty handovers by the HandoverReminder.
+type ReminderScheduler struct {
+	db       *gorm.DB
+	policy   ReminderPolicy
+	interval time.Duration
+}
+
+func NewReminderScheduler(db *gorm.DB, policy ReminderPolicy, interval time.Duration) *ReminderScheduler {
+	return &ReminderScheduler{
+		db:       db,
+		policy:   policy,
+		interval: interval,
+	}
+}
+
+// Start runs the scheduler periodically until the context is cancelled
+func (s *ReminderScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Run(); err != nil {
+			log.Printf("Reminder scheduler failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run sends every reminder that is due
+func (s *ReminderScheduler) Run() error {
+	now := time.Now()
+
+	var errs []error
+	if s.policy.UpcomingDays > 0 {
+		errs = append(errs, s.remindUpcoming(now))
+	}
+	errs = append(errs, s.remindReturns(now))
+	if s.policy.NoticeDays > 0 {
+		errs = append(errs, s.remindLeaveNotices(now))
+	}
+	return errors.Join(errs...)
+}
+
+// sendReminder builds and delivers the notification unless the reminder was
+// already sent to the recipient; with a repeat interval it is sent again once
+// that much time passed. The log and the notification are written together so
+// instances running the scheduler at the same time do not both send it.
+func (s *ReminderScheduler) sendReminder(requestID uuid.UUID, kind models.ReminderKind, recipientID uuid.UUID, repeatAfter time.Duration, build func(tx *gorm.DB) *models.Notification) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		onConflict := clause.OnConflict{
+			Columns:   []clause.Column{{Name: "vacation_request_id"}, {Name: "kind"}, {Name: "recipient_id"}},
+			DoNothing: true,
+		}
+		if repeatAfter > 0 {
+			onConflict = clause.OnConflict{
+				Columns:   []clause.Column{{Name: "vacation_request_id"}, {Name: "kind"}, {Name: "recipient_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"sent_at": now}),
+				Where: clause.Where{Exprs: []clause.Expression{
+					clause.Expr{SQL: "reminder_logs.sent_at <= ?", Vars: []interface{}{now.Add(-repeatAfter)}},
+				}},
+			}
+		}
+
+		result := tx.Clauses(onConflict).Create(&models.ReminderLog{
+			VacationRequestID: requestID,
+			Kind:              kind,
+			RecipientID:       recipientID,
+			SentAt:            now,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to record reminder: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		return Deliver(tx, build(tx))
+	})
+}
+
+// remindUpcoming tells requesters their approved leave is close, pointing out
+// handover items still open; empty handovers are left to the HandoverReminder
+func (s *ReminderScheduler) remindUpcoming(now time.Time) error {
+	var requests []models.VacationRequest
+	if err := s.db.Where("status = ? AND start_date > ? AND start_date <= ?",
+		models.StatusApproved, now, now.AddDate(0, 0, s.policy.UpcomingDays)).
+		Find(&requests).Error; err != nil {
+		return fmt.Errorf("failed to fetch upcoming requests: %w", err)
+	}
+
+	for i := range requests {
+		request := &requests[i]
+
+		var handover models.Handover
+		err := s.db.Preload("Items").Where("vacation_request_id = ?", request.ID).First(&handover).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			log.Printf("Failed to fetch handover of request %s: %v", request.ID, err)
+			continue
+		}
+
+		days := int(startOfDay(request.StartDate).Sub(startOfDay(now)).Hours() / 24)
+		message := fmt.Sprintf("Suas férias começam em %s (em %d dia(s)).", request.StartDate.Format("02/01/2006"), days)
+		open := 0
+		for _, item := range handover.Items {
+			if !item.Completed {
+				open++
+			}
+		}
+		if open > 0 {
+			message += fmt.Sprintf(" Ainda há %d item(ns) em aberto na passagem de bastão.", open)
+		}
+
+		if err := s.sendReminder(request.ID, models.ReminderUpcomingLeave, request.UserID, 0, func(*gorm.DB) *models.Notification {
+			return requestNotice(request.UserID, request, models.NotificationReminder, "", "Férias se Aproximando", message, nil)
+		}); err != nil {
+			log.Printf("Failed to send upcoming leave reminder for request %s: %v", request.ID, err)
+		}
+	}
+	return nil
+}
+
+// returnDate is the first weekday after the leave ends
+func returnDate(request *models.VacationRequest) time.Time {
+	day := startOfDay(request.EndDate).AddDate(0, 0, 1)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// remindReturns tells requesters on leave the day before they are back. A
+// leave ending on a Friday is completed over the weekend, before the reminder
+// is due on Sunday, so completed leaves are reminded as well.
+func (s *ReminderScheduler) remindReturns(now time.Time) error {
+	today := startOfDay(now)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	// The return falls at most a weekend after the end date
+	var requests []models.VacationRequest
+	if err := s.db.Where("status IN ? AND end_date >= ? AND end_date < ?",
+		[]models.VacationStatus{models.StatusInProgress, models.StatusCompleted},
+		today.AddDate(0, 0, -3), tomorrow.AddDate(0, 0, 1)).
+		Find(&requests).Error; err != nil {
+		return fmt.Errorf("failed to fetch requests ending soon: %w", err)
+	}
+
+	for i := range requests {
+		request := &requests[i]
+		back := returnDate(request)
+		if !back.After(today) || back.After(tomorrow) {
+			continue
+		}
+
+		message := fmt.Sprintf("Suas férias terminam em %s. Seu retorno está previsto para %s. Confira a passagem de bastão para retomar as atividades.",
+			request.EndDate.Format("02/01/2006"), back.Format("02/01/2006"))
+		if err := s.sendReminder(request.ID, models.ReminderReturn, request.UserID, 0, func(*gorm.DB) *models.Notification {
+			return requestNotice(request.UserID, request, models.NotificationReminder, "", "Retorno de Férias", message, nil)
+		}); err != nil {
+			log.Printf("Failed to send return reminder for request %s: %v", request.ID, err)
+		}
+	}
+	return nil
+}
+
+// remindLeaveNotices warns HR of vacation notices due soon, which must reach
+// the employee NoticeDays before the leave starts
+func (s *ReminderScheduler) remindLeaveNotices(now time.Time) error {
+	var requests []models.VacationRequest
+	if err := s.db.Preload("User").
+		Where("status = ? AND leave_type = ? AND start_date > ? AND start_date <= ?",
+			models.StatusApproved, models.LeaveTypeVacation, now,
+			now.AddDate(0, 0, s.policy.NoticeDays+s.policy.NoticeLeadDays)).
+		Find(&requests).Error; err != nil {
+		return fmt.Errorf("failed to fetch requests needing notice: %w", err)
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+
+	var recipients []uuid.UUID
+	if err := s.db.Model(&models.User{}).Where("role = ? AND active = ?", models.RoleHR, true).
+		Pluck("id", &recipients).Error; err != nil {
+		return fmt.Errorf("failed to fetch HR users: %w", err)
+	}
+
+	for i := range requests {
+		request := &requests[i]
+		deadline := startOfDay(request.StartDate).AddDate(0, 0, -s.policy.NoticeDays)
+
+		message := fmt.Sprintf("Emita o aviso de férias de %s até %s (férias de %s a %s).",
+			request.User.Name,
+			deadline.Format("02/01/2006"),
+			request.StartDate.Format("02/01/2006"),
+			request.EndDate.Format("02/01/2006"))
+		if deadline.Before(startOfDay(now)) {
+			message = fmt.Sprintf("O aviso de férias de %s deveria ter sido emitido até %s (férias de %s a %s). Emita-o o quanto antes.",
+				request.User.Name,
+				deadline.Format("02/01/2006"),
+				request.StartDate.Format("02/01/2006"),
+				request.EndDate.Format("02/01/2006"))
+		}
+
+		for _, recipient := range recipients {
+			if err := s.sendReminder(request.ID, models.ReminderLeaveNotice, recipient, 0, func(*gorm.DB) *models.Notification {
+				return requestNotice(recipient, request, models.NotificationReminder, "", "Aviso de Férias", message, nil)
+			}); err != nil {
+				log.Printf("Failed to send notice reminder for request %s: %v", request.ID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// notifyUsers sends the same notification to each recipient once
+func notifyUsers(db *gorm.DB, recipients []uuid.UUID, notificationType models.NotificationType, title, message string) {
+	sent := map[uuid.UUID]bool{}
+	for _, recipient := range recipients {
+		if sent[recipient] {
+			continue
+		}
+		sent[recipient] = true
+		if err := Notify(db, recipient, notificationType, title, message); err != nil {
+			log.Printf("Failed to send notification \"%s\" to user %s: %v", title, recipient, err)
+		}
+	}
+}
+
+// coveringSubstitute returns the substitute still expected to cover the
+// request, if any
+func coveringSubstitute(request *models.VacationRequest) *uuid.UUID {
+	if request.SubstituteID == nil || request.SubstituteStatus == models.SubstituteDeclined {
+		return nil
+	}
+	return request.SubstituteID
+}
+
+// CurrentStep loads the step of a request awaiting a decision, or nil when
+// there is none
+func CurrentStep(db *gorm.DB, requestID uuid.UUID) (*models.ApprovalStep, error) {
+	var step models.ApprovalStep
+	if err := db.Where("vacation_request_id = ? AND status = ?", requestID, models.StepPending).
+		Order("position ASC").First(&step).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &step, nil
+}
+
+// NotifyStepApprovers informs whoever may decide the step about a change to
+// a request they have not decided yet. An empty template emails the title and
+// message as they are.
+func NotifyStepApprovers(db *gorm.DB, request *models.VacationRequest, step *models.ApprovalStep, template, title, message string) {
+	for _, recipient := range approverRecipients(db, step) {
+		if err := Deliver(db, requestNotice(recipient, request, models.NotificationRequest, template, title, message, nil)); err != nil {
+			log.Printf("Failed to notify approver %s of request %s: %v", recipient, request.ID, err)
+		}
+	}
+}
+
+// notifyDecision tells the requester how the request was decided, and the
+// substitute whether the coverage is confirmed
+func notifyDecision(db *gorm.DB, request *models.VacationRequest) {
+	period := fmt.Sprintf("%s a %s", request.StartDate.Format("02/01/2006"), request.EndDate.Format("02/01/2006"))
+	substitute := coveringSubstitute(request)
+
+	if request.Status == models.StatusApproved {
+		message := fmt.Sprintf("Suas férias de %s foram aprovadas.", period)
+		if request.ApprovalComment != "" {
+			message += " Comentário: " + request.ApprovalComment
+		}
+		if err := Deliver(db, requestNotice(request.UserID, request, models.NotificationApproval,
+			models.TemplateRequestApproved, "Férias Aprovadas", message, nil)); err != nil {
+			log.Printf("Failed to notify approval of request %s: %v", request.ID, err)
+		}
+
+		if substitute != nil {
+			notifyUsers(db, []uuid.UUID{*substitute}, models.NotificationSubstitute, "Substituição Confirmada",
+				fmt.Sprintf("As férias de %s (%s) foram aprovadas. Você será o(a) substituto(a) no período.", request.User.Name, period))
+		}
+		return
+	}
+
+	message := fmt.Sprintf("Sua solicitação de férias de %s foi rejeitada.", period)
+	if request.ApprovalComment != "" {
+		message += " Motivo: " + request.ApprovalComment
+	}
+	if err := Deliver(db, requestNotice(request.UserID, request, models.NotificationRejection,
+		models.TemplateRequestRejected, "Férias Rejeitadas", message, nil)); err != nil {
+		log.Printf("Failed to notify rejection of request %s: %v", request.ID, err)
+	}
+
+	if substitute != nil {
+		notifyUsers(db, []uuid.UUID{*substitute}, models.NotificationSubstitute, "Substituição Cancelada",
+			fmt.Sprintf("A solicitação de férias de %s (%s), na qual você era substituto(a), foi rejeitada.", request.User.Name, period))
+	}
+}
+
+// notifyStepApproved tells the requester their request moved to the next level
+func notifyStepApproved(db *gorm.DB, request *models.VacationRequest, step, next *models.ApprovalStep) {
+	message := fmt.Sprintf("Sua solicitação de férias de %s a %s foi aprovada na etapa %d e segue para a etapa %d.",
+		request.StartDate.Format("02/01/2006"),
+		request.EndDate.Format("02/01/2006"),
+		step.Position,
+		next.Position)
+	notifyUsers(db, []uuid.UUID{request.UserID}, models.NotificationApproval, "Etapa Aprovada", message)
+}
+
+// notifyCancellation releases the substitute of a cancelled request and tells
+// whoever was about to decide the step that the request is gone
+func notifyCancellation(db *gorm.DB, request *models.VacationRequest, step *models.ApprovalStep) {
+	if request.SubstituteID != nil {
+		notifyUsers(db, []uuid.UUID{*request.SubstituteID}, models.NotificationSubstitute, "Substituição Cancelada",
+			fmt.Sprintf("A solicitação de férias de %s a %s, na qual você era substituto(a), foi cancelada.",
+				request.StartDate.Format("02/01/2006"), request.EndDate.Format("02/01/2006")))
+	}
+
+	if step != nil {
+		NotifyStepApprovers(db, request, step, models.TemplateRequestCancelled, "Solicitação Cancelada",
+			fmt.Sprintf("A solicitação de férias de %s (%s a %s) foi cancelada pelo(a) colaborador(a).",
+				request.User.Name,
+				request.StartDate.Format("02/01/2006"),
+				request.EndDate.Format("02/01/2006")))
+	}
+}
+
+// notifyLeaveEvent tells the requester's manager and substitute that a leave
+// started or ended
+func notifyLeaveEvent(db *gorm.DB, request *models.VacationRequest, event models.VacationEvent) {
+	var recipients []uuid.UUID
+	if request.User.ManagerID != nil {
+		recipients = append(recipients, *request.User.ManagerID)
+	}
+
+	switch event {
+	case models.EventStart:
+		if substitute := coveringSubstitute(request); substitute != nil {
+			recipients = append(recipients, *substitute)
+		}
+		notifyUsers(db, recipients, models.NotificationSystem, "Férias Iniciadas",
+			fmt.Sprintf("%s está de férias a partir de hoje, com retorno após %s.",
+				request.User.Name, request.EndDate.Format("02/01/2006")))
+	case models.EventComplete:
+		notifyUsers(db, recipients, models.NotificationSystem, "Férias Concluídas",
+			fmt.Sprintf("As férias de %s terminaram em %s.", request.User.Name, request.EndDate.Format("02/01/2006")))
+	}
+}
+
+// notifySubstituteRequest asks the substitute to accept or decline covering the request
+func notifySubstituteRequest(db *gorm.DB, request *models.VacationRequest) {
+	notifyUsers(db, []uuid.UUID{*request.SubstituteID}, models.NotificationSubstitute, "Pedido de Substituição",
+		fmt.Sprintf("%s indicou você como substituto(a) durante as férias de %s a %s. Aceite ou recuse a cobertura.",
+			request.User.Name,
+			request.StartDate.Format("02/01/2006"),
+			request.EndDate.Format("02/01/2006")))
+}
+
+// notifyRuleApproval tells the requester their request was approved by a team
+// rule, and the manager until when the approval may be revoked
+func notifyRuleApproval(db *gorm.DB, request *models.VacationRequest, rule *models.AutoApprovalRule) {
+	notifyDecision(db, request)
+	if rule == nil || request.RevocableUntil == nil {
+		return
+	}
+
+	// The manager is informed, not asked
+	notifyUsers(db, []uuid.UUID{rule.ManagerID}, models.NotificationApproval, "Férias Aprovadas Automaticamente",
+		fmt.Sprintf("As férias de %s (%s a %s) foram aprovadas automaticamente pela regra \"%s\". Você pode revogar a aprovação até %s.",
+			request.User.Name,
+			request.StartDate.Format("02/01/2006"),
+			request.EndDate.Format("02/01/2006"),
+			rule.Name,
+			request.RevocableUntil.Format("02/01/2006 15:04")))
+}
+
+// notifyExpiredApproval tells the requester and the approvers that the request
+// was approved because nobody decided it in time
+func notifyExpiredApproval(db *gorm.DB, request *models.VacationRequest) {
+	recipients := []uuid.UUID{request.UserID}
+	for _, step := range request.ApprovalSteps {
+		if step.ApproverID != nil {
+			recipients = append(recipients, *step.ApproverID)
+		}
+	}
+	notifyUsers(db, recipients, models.NotificationApproval, "Férias Aprovadas Automaticamente",
+		fmt.Sprintf("A solicitação de férias de %s (%s a %s) foi aprovada automaticamente por falta de resposta dentro do prazo.",
+			request.User.Name,
+			request.StartDate.Format("02/01/2006"),
+			request.EndDate.Format("02/01/2006")))
+}
+
+// notifyRevocation tells the requester the automatic approval was revoked
+func notifyRevocation(db *gorm.DB, request *models.VacationRequest, comment string) {
+	notifyUsers(db, []uuid.UUID{request.UserID}, models.NotificationRejection, "Aprovação Revogada",
+		fmt.Sprintf("A aprovação automática das suas férias de %s a %s foi revogada. Motivo: %s",
+			request.StartDate.Format("02/01/2006"),
+			request.EndDate.Format("02/01/2006"),
+			comment))
+}
+
+// notifyInterruption tells the requester, the manager and the substitute of
+// the earlier return, except whoever recorded the interruption
+func notifyInterruption(db *gorm.DB, request *models.VacationRequest, actorID *uuid.UUID, comment string) {
+	isActor := func(id uuid.UUID) bool {
+		return actorID != nil && *actorID == id
+	}
+	returnDate := request.EndDate.AddDate(0, 0, 1).Format("02/01/2006")
+
+	if !isActor(request.UserID) {
+		notifyUsers(db, []uuid.UUID{request.UserID}, models.NotificationSystem, "Férias Interrompidas",
+			fmt.Sprintf("Suas férias foram interrompidas. Retorno em %s. Motivo: %s", returnDate, comment))
+	}
+
+	// The manager and the substitute plan around the earlier return
+	var others []uuid.UUID
+	if request.User.ManagerID != nil && !isActor(*request.User.ManagerID) {
+		others = append(others, *request.User.ManagerID)
+	}
+	if substitute := coveringSubstitute(request); substitute != nil && !isActor(*substitute) {
+		others = append(others, *substitute)
+	}
+	notifyUsers(db, others, models.NotificationSystem, "Férias Interrompidas",
+		fmt.Sprintf("As férias de %s foram interrompidas. Retorno em %s.", request.User.Name, returnDate))
+}
+
+// notifySubstituteResponse tells the requester and their manager whether the
+// substitute accepted to cover the leave
+func notifySubstituteResponse(db *gorm.DB, request *models.VacationRequest, substituteID *uuid.UUID, accepted bool, comment string) {
+	title := "Substituição Aceita"
+	verb := "aceitou"
+	if !accepted {
+		title = "Substituição Recusada"
+		verb = "recusou"
+	}
+	var substitute models.User
+	if substituteID != nil {
+		if err := db.Where("id = ?", *substituteID).First(&substitute).Error; err != nil {
+			log.Printf("Failed to load substitute %s of request %s: %v", *substituteID, request.ID, err)
+		}
+	}
+	message := fmt.Sprintf("%s %s cobrir as férias de %s de %s a %s.",
+		substitute.Name, verb, request.User.Name,
+		request.StartDate.Format("02/01/2006"),
+		request.EndDate.Format("02/01/2006"))
+	if comment != "" {
+		message += " Comentário: " + comment
+	}
+
+	recipients := []uuid.UUID{request.UserID}
+	if request.User.ManagerID != nil {
+		recipients = append(recipients, *request.User.ManagerID)
+	}
+	notifyUsers(db, recipients, models.NotificationSubstitute, title, message)
+}
+
+// threadParticipants lists everyone following the thread: requester, manager,
+// substitute, assigned approvers, previous authors and mentioned users
+func threadParticipants(db *gorm.DB, request *models.VacationRequest) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{request.UserID}
+	if request.User.ManagerID != nil {
+		ids = append(ids, *request.User.ManagerID)
+	}
+	if request.SubstituteID != nil {
+		ids = append(ids, *request.SubstituteID)
+	}
+
+	var approverIDs []uuid.UUID
+	if err := db.Model(&models.ApprovalStep{}).
+		Where("vacation_request_id = ? AND approver_id IS NOT NULL", request.ID).
+		Pluck("approver_id", &approverIDs).Error; err != nil {
+		return nil, err
+	}
+	ids = append(ids, approverIDs...)
+
+	var authorIDs []uuid.UUID
+	if err := db.Model(&models.RequestComment{}).
+		Where("vacation_request_id = ?", request.ID).
+		Distinct().Pluck("author_id", &authorIDs).Error; err != nil {
+		return nil, err
+	}
+	ids = append(ids, authorIDs...)
+
+	var mentionedIDs []uuid.UUID
+	if err := db.Model(&models.CommentMention{}).
+		Joins("JOIN request_comments ON request_comments.id = comment_mentions.comment_id").
+		Where("request_comments.vacation_request_id = ?", request.ID).
+		Distinct().Pluck("comment_mentions.user_id", &mentionedIDs).Error; err != nil {
+		return nil, err
+	}
+	ids = append(ids, mentionedIDs...)
+
+	seen := map[uuid.UUID]bool{}
+	var participants []uuid.UUID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			participants = append(participants, id)
+		}
+	}
+	return participants, nil
+}
+
+// notifyComment tells mentioned users and the other participants about a new message
+func notifyComment(db *gorm.DB, request *models.VacationRequest, comment *models.RequestComment) {
+	participants, err := threadParticipants(db, request)
+	if err != nil {
+		log.Printf("Failed to resolve participants of request %s: %v", request.ID, err)
+		return
+	}
+
+	mentioned := map[uuid.UUID]bool{}
+	for _, mention := range comment.Mentions {
+		mentioned[mention.UserID] = true
+	}
+
+	period := fmt.Sprintf("%s a %s",
+		request.StartDate.Format("02/01/2006"),
+		request.EndDate.Format("02/01/2006"))
+
+	for _, recipient := range participants {
+		if recipient == comment.AuthorID {
+			continue
+		}
+
+		title := "Novo Comentário"
+		message := fmt.Sprintf("%s comentou na solicitação de férias de %s (%s).",
+			comment.Author.Name, request.User.Name, period)
+		if mentioned[recipient] {
+			title = "Você foi mencionado(a)"
+			message = fmt.Sprintf("%s mencionou você na solicitação de férias de %s (%s).",
+				comment.Author.Name, request.User.Name, period)
+		}
+
+		if err := Notify(db, recipient, models.NotificationComment, title, message); err != nil {
+			log.Printf("Failed to notify comment %s to user %s: %v", comment.ID, recipient, err)
+		}
+	}
+}
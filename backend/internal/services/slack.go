@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSlackDisabled         = errors.New("slack integration is not configured")
+	ErrInvalidSlackSignature = errors.New("invalid slack request signature")
+	ErrSlackUserNotLinked    = errors.New("slack user has no matching active user")
+)
+
+// slackMaxClockSkew is how old a signed request may be, to refuse replays
+const slackMaxClockSkew = 5 * time.Minute
+
+// SlackConfig configures the slash command and interactive message endpoints
+type SlackConfig struct {
+	// SigningSecret verifies requests; the endpoints are disabled while it is empty
+	SigningSecret string
+	// BotToken reads the email of Slack users (users:read.email scope)
+	BotToken string
+	// APIURL is the Web API base, replaced by a stand-in server in development
+	APIURL string
+}
+
+var (
+	slackConfig SlackConfig
+	slackClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// ConfigureSlack enables the Slack endpoints
+func ConfigureSlack(config SlackConfig) {
+	config.APIURL = strings.TrimRight(config.APIURL, "/")
+	slackConfig = config
+}
+
+// VerifySlackRequest checks the X-Slack-Signature of a request body: the hex
+// HMAC-SHA256 of "v0:<timestamp>:<body>" keyed with the signing secret
+func VerifySlackRequest(timestamp, signature string, body []byte) error {
+	if slackConfig.SigningSecret == "" {
+		return ErrSlackDisabled
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSlackSignature
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > slackMaxClockSkew || age < -slackMaxClockSkew {
+		return ErrInvalidSlackSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(slackConfig.SigningSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSlackSignature
+	}
+	return nil
+}
+
+// ResolveSlackUser returns the active user linked to the Slack account,
+// linking it by email the first time it is seen
+func ResolveSlackUser(db *gorm.DB, teamID, slackUserID string) (*models.User, error) {
+	var identity models.ChatIdentity
+	err := db.Preload("User").
+		Where("provider = ? AND workspace_id = ? AND external_id = ?", models.ChatProviderSlack, teamID, slackUserID).
+		First(&identity).Error
+	if err == nil {
+		if !identity.User.Active {
+			return nil, ErrSlackUserNotLinked
+		}
+		return &identity.User, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to fetch chat identity: %w", err)
+	}
+
+	email, err := slackUserEmail(slackUserID)
+	if err != nil {
+		return nil, err
+	}
+	if email == "" {
+		return nil, ErrSlackUserNotLinked
+	}
+
+	var user models.User
+	if err := db.Where("LOWER(email) = LOWER(?) AND active = ?", email, true).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrSlackUserNotLinked
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	identity = models.ChatIdentity{
+		Provider:    models.ChatProviderSlack,
+		WorkspaceID: teamID,
+		ExternalID:  slackUserID,
+		UserID:      user.ID,
+		Email:       email,
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&identity).Error; err != nil {
+		return nil, fmt.Errorf("failed to link chat identity: %w", err)
+	}
+	return &user, nil
+}
+
+// slackUserEmail reads the profile email of a Slack user
+func slackUserEmail(slackUserID string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, slackConfig.APIURL+"/users.info?user="+url.QueryEscape(slackUserID), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+slackConfig.BotToken)
+
+	resp, err := slackClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up slack user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		User  struct {
+			Deleted bool `json:"deleted"`
+			Profile struct {
+				Email string `json:"email"`
+			} `json:"profile"`
+		} `json:"user"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to read slack user: %w", err)
+	}
+	if !body.OK {
+		if body.Error == "user_not_found" {
+			return "", ErrSlackUserNotLinked
+		}
+		return "", fmt.Errorf("failed to look up slack user: %s", body.Error)
+	}
+	if body.User.Deleted {
+		return "", ErrSlackUserNotLinked
+	}
+	return body.User.Profile.Email, nil
+}
+
+// PostSlackResponse sends a message to the response URL of a command or an
+// interaction
+func PostSlackResponse(responseURL string, message *models.SlackMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	resp, err := slackClient.Post(responseURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OpenSlackView opens a modal for the user who clicked a button. The trigger
+// ID of the click is only valid for a few seconds.
+func OpenSlackView(triggerID string, view *models.SlackView) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"trigger_id": triggerID,
+		"view":       view,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, slackConfig.APIURL+"/views.open", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+slackConfig.BotToken)
+
+	resp, err := slackClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to open slack view: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to read slack response: %w", err)
+	}
+	if !body.OK {
+		return fmt.Errorf("failed to open slack view: %s", body.Error)
+	}
+	return nil
+}
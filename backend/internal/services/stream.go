@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// streamChannel is the PostgreSQL channel announcing new stream events to
+// every API instance
+const streamChannel = "stream_events"
+
+// streamBuffer is how many events a slow client may lag behind before it is
+// disconnected and has to resume from its last event ID
+const streamBuffer = 64
+
+var ErrStreamUnavailable = errors.New("real-time stream is not running")
+
+// PublishStreamEvent records an event for the user and announces it. Pass the
+// transaction that made the change: the event is kept, and PostgreSQL only
+// delivers the announcement, when it commits.
+func PublishStreamEvent(db *gorm.DB, userID uuid.UUID, eventType models.StreamEventType, payload interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event := models.StreamEvent{
+		UserID:  userID,
+		Type:    eventType,
+		Payload: string(encoded),
+	}
+	if err := db.Create(&event).Error; err != nil {
+		return fmt.Errorf("failed to store stream event: %w", err)
+	}
+	if err := db.Exec("SELECT pg_notify(?, ?)", streamChannel, strconv.FormatInt(event.ID, 10)).Error; err != nil {
+		return fmt.Errorf("failed to announce stream event: %w", err)
+	}
+	return nil
+}
+
+// publishRequestStatus pushes a status change to the requester and their
+// manager
+func publishRequestStatus(db *gorm.DB, entry *models.RequestHistory) error {
+	var request models.VacationRequest
+	if err := db.Preload("User").Select("id", "user_id").Where("id = ?", entry.VacationRequestID).First(&request).Error; err != nil {
+		return fmt.Errorf("failed to load vacation request: %w", err)
+	}
+
+	recipients := []uuid.UUID{request.UserID}
+	if request.User.ManagerID != nil {
+		recipients = append(recipients, *request.User.ManagerID)
+	}
+	payload := models.RequestStatusEvent{
+		VacationRequestID: entry.VacationRequestID.String(),
+		Action:            string(entry.Action),
+		FromStatus:        string(entry.FromStatus),
+		ToStatus:          string(entry.ToStatus),
+	}
+	for _, recipient := range recipients {
+		if err := PublishStreamEvent(db, recipient, models.StreamRequestStatus, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamSubscription receives the events of one user as they are published.
+// Events is closed when the subscriber falls too far behind.
+type StreamSubscription struct {
+	UserID uuid.UUID
+	Events chan models.StreamEvent
+	hub    *StreamHub
+	closed bool
+}
+
+// Close stops the subscription
+func (s *StreamSubscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// StreamHub listens for stream events announced by any instance and hands
+// them to the clients connected to this one
+type StreamHub struct {
+	db          *gorm.DB
+	databaseURL string
+	retention   time.Duration
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[*StreamSubscription]bool
+	lastID      int64
+}
+
+func NewStreamHub(db *gorm.DB, databaseURL string, retention time.Duration) *StreamHub {
+	return &StreamHub{
+		db:          db,
+		databaseURL: databaseURL,
+		retention:   retention,
+		subscribers: map[uuid.UUID]map[*StreamSubscription]bool{},
+	}
+}
+
+var streamHub *StreamHub
+
+// SetStreamHub makes SubscribeStream use the hub
+func SetStreamHub(hub *StreamHub) {
+	streamHub = hub
+}
+
+// SubscribeStream starts receiving the user's events on this instance
+func SubscribeStream(userID uuid.UUID) (*StreamSubscription, error) {
+	if streamHub == nil {
+		return nil, ErrStreamUnavailable
+	}
+	return streamHub.subscribe(userID), nil
+}
+
+// ReplayStream loads the user's events after the given ID, oldest first
+func ReplayStream(db *gorm.DB, userID uuid.UUID, afterID int64) ([]models.StreamEvent, error) {
+	var events []models.StreamEvent
+	if err := db.Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (h *StreamHub) subscribe(userID uuid.UUID) *StreamSubscription {
+	subscription := &StreamSubscription{
+		UserID: userID,
+		Events: make(chan models.StreamEvent, streamBuffer),
+		hub:    h,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = map[*StreamSubscription]bool{}
+	}
+	h.subscribers[userID][subscription] = true
+	return subscription
+}
+
+func (h *StreamHub) unsubscribe(subscription *StreamSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(subscription)
+}
+
+// drop must be called with the lock held
+func (h *StreamHub) drop(subscription *StreamSubscription) {
+	if subscription.closed {
+		return
+	}
+	subscription.closed = true
+	close(subscription.Events)
+
+	delete(h.subscribers[subscription.UserID], subscription)
+	if len(h.subscribers[subscription.UserID]) == 0 {
+		delete(h.subscribers, subscription.UserID)
+	}
+}
+
+func (h *StreamHub) dispatch(event models.StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if event.ID > h.lastID {
+		h.lastID = event.ID
+	}
+	for subscription := range h.subscribers[event.UserID] {
+		select {
+		case subscription.Events <- event:
+		default:
+			h.drop(subscription)
+		}
+	}
+}
+
+// Start listens for events until the context is cancelled, reconnecting when
+// the connection is lost, and prunes events past the retention period
+func (h *StreamHub) Start(ctx context.Context) {
+	if err := h.db.Model(&models.StreamEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&h.lastID).Error; err != nil {
+		log.Printf("Failed to read the last stream event: %v", err)
+	}
+
+	go h.prune(ctx)
+
+	for {
+		if err := h.listen(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Stream listener stopped: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (h *StreamHub) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, h.databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+streamChannel); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	// Deliver what was published while the listener was down
+	if err := h.catchUp(); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		id, err := strconv.ParseInt(notification.Payload, 10, 64)
+		if err != nil {
+			log.Printf("Ignoring malformed stream announcement %q", notification.Payload)
+			continue
+		}
+
+		var event models.StreamEvent
+		if err := h.db.Where("id = ?", id).First(&event).Error; err != nil {
+			log.Printf("Failed to load stream event %d: %v", id, err)
+			continue
+		}
+		h.dispatch(event)
+	}
+}
+
+func (h *StreamHub) catchUp() error {
+	h.mu.Lock()
+	lastID := h.lastID
+	h.mu.Unlock()
+
+	var events []models.StreamEvent
+	if err := h.db.Where("id > ?", lastID).Order("id ASC").Find(&events).Error; err != nil {
+		return fmt.Errorf("failed to load missed stream events: %w", err)
+	}
+	for _, event := range events {
+		h.dispatch(event)
+	}
+	return nil
+}
+
+func (h *StreamHub) prune(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := h.db.Where("created_at < ?", time.Now().Add(-h.retention)).
+			Delete(&models.StreamEvent{}).Error; err != nil {
+			log.Printf("Failed to prune stream events: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
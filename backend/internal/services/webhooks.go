@@ -0,0 +1,337 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerenciador-ferias/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Headers of webhook requests. The signature is the hex HMAC-SHA256, keyed
+// with the subscription secret, of the timestamp, a dot and the raw body.
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookResponseLimit is how much of a subscriber's response is kept in the log
+const webhookResponseLimit = 1024
+
+// GenerateWebhookSecret creates a random signing secret
+func GenerateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// SignWebhook computes the signature header value of a payload
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PublishWebhookEvent queues the event for every active subscription to it.
+// Pass the transaction that made the change so the event is only sent when
+// the change commits.
+func PublishWebhookEvent(db *gorm.DB, event string, data interface{}) error {
+	var subscriptions []models.WebhookSubscription
+	encodedEvent, _ := json.Marshal([]string{event})
+	if err := db.Where("active = ? AND events @> ?::jsonb", true, string(encodedEvent)).
+		Find(&subscriptions).Error; err != nil {
+		return fmt.Errorf("failed to fetch webhook subscriptions: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	eventID := uuid.New()
+	payload, err := json.Marshal(models.WebhookPayload{
+		ID:        eventID.String(),
+		Event:     event,
+		CreatedAt: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, subscription := range subscriptions {
+		delivery := models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        eventID,
+			Event:          event,
+			Payload:        string(payload),
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  &now,
+		}
+		if err := db.Create(&delivery).Error; err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+
+	if webhookSender != nil {
+		webhookSender.wakeUp()
+	}
+	return nil
+}
+
+// publishRequestEvent sends the webhook matching a history entry, if any
+func publishRequestEvent(db *gorm.DB, entry *models.RequestHistory) error {
+	var event string
+	switch {
+	case entry.Action == models.HistoryCreated:
+		event = models.WebhookRequestCreated
+	case entry.ToStatus == entry.FromStatus:
+		return nil
+	case entry.ToStatus == models.StatusApproved:
+		event = models.WebhookRequestApproved
+	case entry.ToStatus == models.StatusRejected:
+		event = models.WebhookRequestRejected
+	case entry.ToStatus == models.StatusCancelled:
+		event = models.WebhookRequestCancelled
+	default:
+		return nil
+	}
+
+	var request models.VacationRequest
+	if err := db.Preload("User").Where("id = ?", entry.VacationRequestID).First(&request).Error; err != nil {
+		return fmt.Errorf("failed to load vacation request: %w", err)
+	}
+	// The event reports the status the entry recorded, even if the request
+	// changed again before the relay ran
+	data := request.ToWebhookData()
+	data.Status = string(entry.ToStatus)
+	return PublishWebhookEvent(db, event, data)
+}
+
+// PublishUserUpdated records a change to the user in the outbox; the
+// user.updated subscribers receive the user's data as it is when relayed
+func PublishUserUpdated(db *gorm.DB, userID uuid.UUID) error {
+	return RecordEvent(db, models.AggregateUser, userID, models.EventUserUpdated, struct{}{})
+}
+
+// Redeliver queues the event of a past delivery again, as a new delivery
+func Redeliver(db *gorm.DB, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	redelivery := models.WebhookDelivery{
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Payload:        delivery.Payload,
+		Status:         models.DeliveryPending,
+		NextAttemptAt:  &now,
+		RedeliveryOf:   &delivery.ID,
+	}
+	if err := db.Create(&redelivery).Error; err != nil {
+		return nil, err
+	}
+
+	if webhookSender != nil {
+		webhookSender.wakeUp()
+	}
+	return &redelivery, nil
+}
+
+type WebhookSenderConfig struct {
+	// MaxAttempts is how many times a delivery is tried before it is failed
+	MaxAttempts int
+	// RetryDelay is the wait after the first failure, doubled on each retry
+	RetryDelay time.Duration
+	// Timeout bounds a single request
+	Timeout   time.Duration
+	BatchSize int
+}
+
+// WebhookSender posts queued webhook deliveries, retrying failed ones
+type WebhookSender struct {
+	db       *gorm.DB
+	config   WebhookSenderConfig
+	client   *http.Client
+	interval time.Duration
+	wake     chan struct{}
+}
+
+func NewWebhookSender(db *gorm.DB, config WebhookSenderConfig, interval time.Duration) *WebhookSender {
+	return &WebhookSender{
+		db:       db,
+		config:   config,
+		client:   &http.Client{Timeout: config.Timeout},
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+var webhookSender *WebhookSender
+
+// SetWebhookSender makes new webhook events wake the sender
+func SetWebhookSender(s *WebhookSender) {
+	webhookSender = s
+}
+
+func (s *WebhookSender) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start sends due deliveries periodically, and as soon as new ones are
+// queued, until the context is cancelled
+func (s *WebhookSender) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Run(); err != nil {
+			log.Printf("Webhook delivery failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Run attempts every delivery that is due
+func (s *WebhookSender) Run() error {
+	for {
+		deliveries, err := s.claim()
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		for i := range deliveries {
+			s.deliver(&deliveries[i])
+		}
+	}
+}
+
+// claim picks a batch of due deliveries and pushes their next attempt out, so
+// other instances skip them while they are being sent
+func (s *WebhookSender) claim() ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(lockSkipLocked).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+			Order("next_attempt_at ASC").
+			Limit(s.config.BatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		lease := time.Now().Add(s.config.Timeout + time.Minute)
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", lease).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// deliver makes one attempt and records its outcome
+func (s *WebhookSender) deliver(delivery *models.WebhookDelivery) {
+	status, body, sendErr := s.post(delivery)
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":        delivery.Attempts + 1,
+		"response_status": status,
+		"response_body":   body,
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.DeliverySent
+		updates["delivered_at"] = now
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = ""
+	case delivery.Attempts+1 >= s.config.MaxAttempts:
+		updates["status"] = models.DeliveryFailed
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = sendErr.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(s.config.RetryDelay << delivery.Attempts)
+		updates["last_error"] = sendErr.Error()
+	}
+	if sendErr != nil {
+		log.Printf("Failed to deliver webhook %s (attempt %d): %v", delivery.ID, delivery.Attempts+1, sendErr)
+	}
+
+	if err := s.db.Model(delivery).Updates(updates).Error; err != nil {
+		log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// post sends the delivery and returns the response status and the start of
+// its body
+func (s *WebhookSender) post(delivery *models.WebhookDelivery) (int, string, error) {
+	var subscription models.WebhookSubscription
+	if err := s.db.Where("id = ?", delivery.SubscriptionID).First(&subscription).Error; err != nil {
+		return 0, "", fmt.Errorf("failed to load subscription: %w", err)
+	}
+	if !subscription.Active {
+		return 0, "", fmt.Errorf("subscription is inactive")
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gerenciador-ferias-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(subscription.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	response := strings.ToValidUTF8(string(raw), "")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, response, fmt.Errorf("subscriber responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, response, nil
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// StreamTicketTTL is how long a stream ticket may be used to connect
+const StreamTicketTTL = time.Minute
+
+// streamTicketKey derives the ticket signing key from the JWT secret, so a
+// ticket is never accepted as an access token and the other way around
+func streamTicketKey(secret string) []byte {
+	return []byte(secret + ":stream-ticket")
+}
+
+// GenerateStreamTicket issues a short-lived token that only opens the
+// real-time stream. It may travel in the query string, where EventSource
+// forces it, because it is useless once the connection is made.
+func GenerateStreamTicket(userID uuid.UUID, email, role, secret string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(StreamTicketTTL)
+	claims := &Claims{
+		UserID: userID,
+		Email:  email,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "vacation-management",
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{"stream"},
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(streamTicketKey(secret))
+	return token, expiresAt, err
+}
+
+func ValidateStreamTicket(ticket, secret string) (*Claims, error) {
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(ticket, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return streamTicketKey(secret), nil
+	}, jwt.WithAudience("stream"))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid stream ticket")
+	}
+
+	return claims, nil
+}
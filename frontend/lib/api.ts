@@ -73,7 +73,8 @@ export interface VacationRequestResponse {
   business_days: number;
   reason?: string;
   emergency_contact: string;
-  status: 'pending' | 'approved' | 'rejected' | 'cancelled';
+  status: 'draft' | 'pending' | 'approved' | 'rejected' | 'cancelled' | 'in_progress' | 'completed' | 'interrupted';
+  version: number;
   created_at: string;
   updated_at: string;
   approval_date?: string;
@@ -224,4 +225,71 @@ export const managerAPI = {
     }>;
   }> =>
     apiCall('/manager/team-stats'),
-};
\ No newline at end of file
+};
+
+// Real-time stream of notifications and request status changes. The stream
+// is opened with a short-lived ticket, so the access token never goes in the
+// URL; when the browser can no longer reconnect with it, a new ticket is
+// requested and the API resumes from the last event received.
+export type StreamEventType = 'notification' | 'request_status';
+
+export interface EventStream {
+  close: () => void;
+}
+
+export const openEventStream = (
+  handlers: Partial<Record<StreamEventType, (data: any) => void>>
+): EventStream | null => {
+  if (!getAuthToken() || typeof EventSource === 'undefined') return null;
+
+  let source: EventSource | null = null;
+  let lastEventId = '';
+  let closed = false;
+  let retry: ReturnType<typeof setTimeout> | undefined;
+
+  const reconnect = (delay: number) => {
+    if (!closed && getAuthToken()) retry = setTimeout(connect, delay);
+  };
+
+  async function connect() {
+    let ticket: string;
+    try {
+      ({ ticket } = await apiCall<{ ticket: string; expires_at: string }>(
+        '/notifications/stream/ticket',
+        { method: 'POST' }
+      ));
+    } catch {
+      reconnect(10000);
+      return;
+    }
+    if (closed) return;
+
+    const params = new URLSearchParams({ ticket });
+    if (lastEventId) params.append('last_event_id', lastEventId);
+    const current = new EventSource(`${API_BASE_URL}/notifications/stream?${params}`);
+    (Object.keys(handlers) as StreamEventType[]).forEach((type) => {
+      current.addEventListener(type, (event) => {
+        const message = event as MessageEvent;
+        if (message.lastEventId) lastEventId = message.lastEventId;
+        handlers[type]?.(JSON.parse(message.data));
+      });
+    });
+    // The ticket has expired by the time the browser retries on its own
+    current.onerror = () => {
+      if (current.readyState === EventSource.CLOSED) {
+        current.close();
+        reconnect(3000);
+      }
+    };
+    source = current;
+  }
+
+  connect();
+  return {
+    close: () => {
+      closed = true;
+      clearTimeout(retry);
+      source?.close();
+    },
+  };
+};